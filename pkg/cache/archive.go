@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+)
+
+type (
+	// Dirs maps names to base directories.
+	// Archive entries are stored relative to a named base directory
+	// such that they can be extracted to a different location.
+	Dirs map[string]string
+
+	// Entry is a path relative to the named base directory `Dir` in [Dirs].
+	Entry struct {
+		Dir  string
+		Path string
+	}
+)
+
+// Archive writes a gzip-compressed tar archive with all entries `entries`
+// to `w`. Directories are added recursively.
+func Archive(w io.Writer, dirs Dirs, entries []Entry) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, e := range entries {
+		base, exists := dirs[e.Dir]
+		if !exists {
+			return errors.New("base directory '%v' for archive entry '%v' not defined", e.Dir, e.Path)
+		}
+
+		err := filepath.WalkDir(path.Join(base, e.Path),
+			func(p string, _ iofs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				rel, err := filepath.Rel(base, p)
+				if err != nil {
+					return err
+				}
+
+				return addToArchive(tw, p, path.Join(e.Dir, filepath.ToSlash(rel)))
+			})
+		if err != nil {
+			return errors.AddContext(err, "could not archive '%v'", path.Join(base, e.Path))
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+func addToArchive(tw *tar.Writer, file string, name string) error {
+	info, err := os.Lstat(file)
+	if err != nil {
+		return err
+	}
+
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err = os.Readlink(file)
+		if err != nil {
+			return err
+		}
+	}
+
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	// Do not store ownership, it is not meaningful on other machines.
+	hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+
+	if err = tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+
+	return err
+}
+
+// Extract extracts a gzip-compressed tar archive from `r`
+// (see [Archive]) into the base directories `dirs`.
+func Extract(r io.Reader, dirs Dirs) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.AddContext(err, "could not read archive")
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			break
+		} else if e != nil {
+			return errors.AddContext(e, "could not read archive")
+		}
+
+		base, dest, e := extractPath(dirs, hdr.Name)
+		if e != nil {
+			return e
+		}
+
+		e = extractEntry(tr, hdr, base, dest)
+		if e != nil {
+			return errors.AddContext(e, "could not extract '%v'", hdr.Name)
+		}
+	}
+
+	return nil
+}
+
+// extractPath returns the base directory and the destination of archive entry `name`.
+func extractPath(dirs Dirs, name string) (base string, dest string, err error) {
+	name = path.Clean(name)
+
+	dir, rel, _ := strings.Cut(name, "/")
+	base, exists := dirs[dir]
+	if !exists {
+		return "", "", errors.New("archive entry '%v' has no known base directory", name)
+	}
+
+	if rel == "" {
+		return base, base, nil
+	}
+
+	if path.IsAbs(rel) || !isLocal(rel) {
+		return "", "", errors.New("archive entry '%v' points outside its base directory", name)
+	}
+
+	return base, path.Join(base, rel), nil
+}
+
+// isLocal reports if the relative path `rel` stays within its base directory.
+func isLocal(rel string) bool {
+	rel = path.Clean(rel)
+
+	return !path.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// checkNoSymlinks returns an error if any existing path component of `dest`
+// below the base directory `base` is a symlink (including `dest` itself
+// if `self` is set), such that nothing is extracted through a link.
+func checkNoSymlinks(base string, dest string, self bool) error {
+	rel, err := filepath.Rel(base, dest)
+	if err != nil {
+		return err
+	}
+
+	p := base
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if !self {
+		parts = parts[:len(parts)-1]
+	}
+
+	for _, part := range parts {
+		if part == "." {
+			continue
+		}
+		p = path.Join(p, part)
+
+		info, e := os.Lstat(p)
+		if os.IsNotExist(e) {
+			return nil
+		} else if e != nil {
+			return e
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return errors.New("refusing to extract '%v' through symlink '%v'", dest, p)
+		}
+	}
+
+	return nil
+}
+
+func extractEntry(tr *tar.Reader, hdr *tar.Header, base string, dest string) error {
+	mode := hdr.FileInfo().Mode()
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := checkNoSymlinks(base, dest, true); err != nil {
+			return err
+		}
+
+		return os.MkdirAll(dest, mode.Perm()|0o700) //nolint:mnd // Keep it writable.
+	case tar.TypeSymlink:
+		// The link must resolve within the base directory.
+		rel, err := filepath.Rel(base, path.Join(path.Dir(dest), hdr.Linkname))
+		if err != nil || path.IsAbs(hdr.Linkname) || !isLocal(filepath.ToSlash(rel)) {
+			return errors.New("symlink '%v' -> '%v' points outside its base directory",
+				hdr.Name, hdr.Linkname)
+		}
+
+		if err = checkNoSymlinks(base, dest, false); err != nil {
+			return err
+		}
+		if err = os.MkdirAll(path.Dir(dest), fs.DefaultPermissionsDir); err != nil {
+			return err
+		}
+		_ = os.Remove(dest)
+
+		return os.Symlink(hdr.Linkname, dest)
+	case tar.TypeReg:
+		if err := checkNoSymlinks(base, dest, false); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(path.Dir(dest), fs.DefaultPermissionsDir); err != nil {
+			return err
+		}
+		// Removes an existing symlink `dest` itself, not its target.
+		_ = os.Remove(dest)
+
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm())
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err = io.CopyN(f, tr, hdr.Size); err != nil {
+			return err
+		}
+
+		return f.Close()
+	default:
+		return errors.New("unsupported archive entry type '%v'", hdr.Typeflag)
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	stderr "errors"
+	"io"
+	"os"
+	"path"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+)
+
+// FilesystemBackend is a cache backend storing entries as files
+// in a directory, e.g. a shared NFS mount.
+type FilesystemBackend struct {
+	dir string
+}
+
+// NewFilesystemBackend creates a cache backend storing entries in directory `dir`.
+func NewFilesystemBackend(dir string) *FilesystemBackend {
+	return &FilesystemBackend{dir: dir}
+}
+
+// Get implements [ICacheBackend].
+func (b *FilesystemBackend) Get(key string, w io.Writer) (bool, error) {
+	if err := validateKey(key); err != nil {
+		return false, err
+	}
+
+	f, err := os.Open(path.Join(b.dir, key))
+	if stderr.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.AddContext(err, "could not open cache entry '%v'", key)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	if err != nil {
+		return false, errors.AddContext(err, "could not read cache entry '%v'", key)
+	}
+
+	return true, nil
+}
+
+// Put implements [ICacheBackend].
+// The entry is written atomically such that concurrent readers
+// never see partial entries.
+func (b *FilesystemBackend) Put(key string, r io.Reader) (err error) {
+	if err = validateKey(key); err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(b.dir, fs.DefaultPermissionsDir)
+	if err != nil {
+		return errors.AddContext(err, "could not create cache directory '%v'", b.dir)
+	}
+
+	f, err := os.CreateTemp(b.dir, "."+key+".*.tmp")
+	if err != nil {
+		return errors.AddContext(err, "could not create cache entry '%v'", key)
+	}
+	defer func() {
+		_ = f.Close()
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = io.Copy(f, r); err != nil {
+		return errors.AddContext(err, "could not write cache entry '%v'", key)
+	}
+
+	if err = f.Chmod(fs.DefaultPermissionsFile); err != nil {
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	err = os.Rename(f.Name(), path.Join(b.dir, key))
+	if err != nil {
+		return errors.AddContext(err, "could not move cache entry '%v' in place", key)
+	}
+
+	return nil
+}
+
+// String implements [ICacheBackend].
+func (b *FilesystemBackend) String() string {
+	return "file://" + b.dir
+}
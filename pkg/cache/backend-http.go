@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+const httpTimeout = 10 * time.Minute
+
+// HTTPBackend is a cache backend which stores entries on a HTTP server
+// with simple `GET <url>/<key>` and `PUT <url>/<key>` requests.
+// A `404` on `GET` means the entry does not exist.
+type HTTPBackend struct {
+	url    *url.URL
+	client *http.Client
+}
+
+// NewHTTPBackend creates a cache backend for the HTTP server at `u`.
+func NewHTTPBackend(u *url.URL) *HTTPBackend {
+	return &HTTPBackend{
+		url:    u,
+		client: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+// Get implements [ICacheBackend].
+func (b *HTTPBackend) Get(key string, w io.Writer) (bool, error) {
+	if err := validateKey(key); err != nil {
+		return false, err
+	}
+
+	u := b.url.JoinPath(key)
+
+	resp, err := b.client.Get(u.String()) //nolint:noctx // Timeout on client.
+	if err != nil {
+		return false, errors.AddContext(err, "could not get cache entry '%v'", key)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, errors.New(
+			"could not get cache entry '%v': status '%v'", key, resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return false, errors.AddContext(err, "could not read cache entry '%v'", key)
+	}
+
+	return true, nil
+}
+
+// Put implements [ICacheBackend].
+func (b *HTTPBackend) Put(key string, r io.Reader) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	u := b.url.JoinPath(key)
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), r) //nolint:noctx // Timeout on client.
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return errors.AddContext(err, "could not put cache entry '%v'", key)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 { //nolint:mnd
+		return errors.New(
+			"could not put cache entry '%v': status '%v'", key, resp.Status)
+	}
+
+	return nil
+}
+
+// String implements [ICacheBackend].
+func (b *HTTPBackend) String() string {
+	return b.url.Redacted()
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+)
+
+// ICacheBackend is the interface for a shared cache which stores
+// archived target outputs under a key (the target fingerprint).
+type ICacheBackend interface {
+	// Get writes the entry `key` to `w`.
+	// Returns `false` if the entry does not exist.
+	Get(key string, w io.Writer) (found bool, err error)
+
+	// Put stores the content of `r` under the entry `key`.
+	Put(key string, r io.Reader) error
+
+	// String returns a description of the backend for logging.
+	String() string
+}
+
+// NewBackend creates a cache backend from the location `loc`:
+//   - `http://...` or `https://...`: A [HTTPBackend].
+//   - `file://...` or a path: A [FilesystemBackend].
+func NewBackend(loc string) (ICacheBackend, error) {
+	switch {
+	case strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://"):
+		u, err := url.Parse(loc)
+		if err != nil {
+			return nil, errors.AddContext(err, "could not parse cache url '%v'", loc)
+		}
+
+		return NewHTTPBackend(u), nil
+	case strings.HasPrefix(loc, "file://"):
+		return NewFilesystemBackend(fs.MakeAbsolute(strings.TrimPrefix(loc, "file://"))), nil
+	case strings.Contains(loc, "://"):
+		return nil, errors.New("cache location '%v' has an unsupported scheme", loc)
+	case loc == "":
+		return nil, errors.New("cache location is empty")
+	}
+
+	return NewFilesystemBackend(fs.MakeAbsolute(loc)), nil
+}
+
+// validateKey validates that the key `key` is safe to use
+// as file name or URL path segment.
+func validateKey(key string) error {
+	if key == "" || key == "." || key == ".." ||
+		strings.ContainsAny(key, `/\?#%`) {
+		return errors.New("invalid cache key '%v'", key)
+	}
+
+	return nil
+}
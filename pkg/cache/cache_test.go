@@ -0,0 +1,204 @@
+//go:build test && (test_small || test_all)
+
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveExtract(t *testing.T) {
+	t.Parallel()
+	src := t.TempDir()
+	out := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(path.Join(src, "bin/sub"), fs.DefaultPermissionsDir))
+	require.NoError(t, os.WriteFile(path.Join(src, "bin/a"), []byte("a"), 0o755))
+	require.NoError(t, os.WriteFile(path.Join(src, "bin/sub/b"), []byte("b"), fs.DefaultPermissionsFile))
+	require.NoError(t, os.Symlink("a", path.Join(src, "bin/link")))
+	require.NoError(t, os.WriteFile(path.Join(out, "c"), []byte("c"), fs.DefaultPermissionsFile))
+
+	var buf bytes.Buffer
+	err := Archive(&buf, Dirs{"root": src, "out": out},
+		[]Entry{{Dir: "root", Path: "bin"}, {Dir: "out", Path: "c"}})
+	require.NoError(t, err)
+
+	dstSrc := t.TempDir()
+	dstOut := t.TempDir()
+	require.NoError(t, Extract(&buf, Dirs{"root": dstSrc, "out": dstOut}))
+
+	c, err := os.ReadFile(path.Join(dstSrc, "bin/sub/b"))
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(c))
+
+	info, err := os.Stat(path.Join(dstSrc, "bin/a"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	l, err := os.Readlink(path.Join(dstSrc, "bin/link"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", l)
+
+	c, err = os.ReadFile(path.Join(dstOut, "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "c", string(c))
+}
+
+func TestExtractOutside(t *testing.T) {
+	t.Parallel()
+	_, _, err := extractPath(Dirs{"root": "/a"}, "root/../../etc/passwd")
+	require.Error(t, err)
+
+	_, _, err = extractPath(Dirs{"root": "/a"}, "other/file")
+	require.Error(t, err)
+
+	base, p, err := extractPath(Dirs{"root": "/a"}, "root/b/c")
+	require.NoError(t, err)
+	assert.Equal(t, "/a", base)
+	assert.Equal(t, "/a/b/c", p)
+}
+
+func writeMaliciousArchive(t *testing.T, hdrs ...*tar.Header) *bytes.Buffer {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, hdr := range hdrs {
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte("evil"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	return &buf
+}
+
+func TestExtractMaliciousSymlinks(t *testing.T) {
+	t.Parallel()
+
+	file := func(name string) *tar.Header {
+		return &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: 4}
+	}
+	link := func(name, target string) *tar.Header {
+		return &tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: target}
+	}
+
+	outside := t.TempDir()
+
+	for _, hdrs := range [][]*tar.Header{
+		// Links leaving the base directory.
+		{link("out/link", outside), file("out/link/x")},
+		{link("out/link", "../.."), file("out/link/x")},
+		{link("out/sub/link", "../../x")},
+	} {
+		base := t.TempDir()
+		err := Extract(writeMaliciousArchive(t, hdrs...), Dirs{"out": base})
+		require.ErrorContains(t, err, "points outside its base directory")
+	}
+
+	// Nothing is extracted through an existing link.
+	base := t.TempDir()
+	require.NoError(t, os.Symlink(outside, path.Join(base, "link")))
+	for _, hdr := range []*tar.Header{
+		file("out/link/x"),
+		{Typeflag: tar.TypeDir, Name: "out/link/dir", Mode: 0o755},
+		link("out/link/y", "."),
+	} {
+		err := Extract(writeMaliciousArchive(t, hdr), Dirs{"out": base})
+		require.ErrorContains(t, err, "through symlink")
+	}
+
+	// A file replaces an existing link instead of writing to its target.
+	require.NoError(t, os.WriteFile(path.Join(outside, "f"), []byte("ok"), fs.DefaultPermissionsFile))
+	require.NoError(t, os.Symlink(path.Join(outside, "f"), path.Join(base, "f")))
+	require.NoError(t, Extract(writeMaliciousArchive(t, file("out/f")), Dirs{"out": base}))
+
+	c, err := os.ReadFile(path.Join(outside, "f"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(c))
+
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Links within the base directory are fine.
+	require.NoError(t, Extract(
+		writeMaliciousArchive(t, link("out/a/link", "../f")), Dirs{"out": t.TempDir()}))
+}
+
+func testBackend(t *testing.T, b ICacheBackend) {
+	var buf bytes.Buffer
+	found, err := b.Get("abc", &buf)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, b.Put("abc", strings.NewReader("content")))
+
+	found, err = b.Get("abc", &buf)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "content", buf.String())
+
+	require.Error(t, b.Put("../abc", strings.NewReader("content")))
+}
+
+func TestFilesystemBackend(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	b, err := NewBackend(dir)
+	require.NoError(t, err)
+	testBackend(t, b)
+
+	assert.FileExists(t, path.Join(dir, "abc"))
+}
+
+func TestHTTPBackend(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	entries := map[string][]byte{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			c, exists := entries[r.URL.Path]
+			if !exists {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+			_, _ = w.Write(c)
+		case http.MethodPut:
+			c, _ := io.ReadAll(r.Body)
+			entries[r.URL.Path] = c
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	b, err := NewBackend(server.URL + "/cache")
+	require.NoError(t, err)
+	testBackend(t, b)
+
+	assert.Contains(t, entries, "/cache/abc")
+}
@@ -1,10 +1,15 @@
 package cleancmd
 
 import (
+	"maps"
 	"os"
+	"slices"
 
 	"github.com/sdsc-ordes/quitsh/pkg/cli"
 	"github.com/sdsc-ordes/quitsh/pkg/cli/general"
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/dag"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
 	"github.com/sdsc-ordes/quitsh/pkg/exec/git"
 	"github.com/sdsc-ordes/quitsh/pkg/log"
 
@@ -14,11 +19,16 @@ import (
 type cleanArgs struct {
 	compArgs   general.ComponentArgs
 	gitIgnored bool
+	outputs    bool
+	targets    []string
 	force      bool
 }
 
 const longDescClean = `
     Clean outputs of components.
+
+    With '--outputs' only the declared 'outputs' of the selected
+    targets (all targets or the ones given by '--target') are removed.
 `
 
 func AddCmd(cli cli.ICLI) {
@@ -48,6 +58,14 @@ func AddCmd(cli cli.ICLI) {
 		BoolVarP(&args.gitIgnored,
 			"git-ignored", "X", false, "Clean Git ignored files in component dir.")
 
+	cleanCmd.Flags().
+		BoolVar(&args.outputs,
+			"outputs", false, "Only clean the declared outputs of the targets.")
+	cleanCmd.Flags().
+		StringArrayVarP(&args.targets,
+			"target", "t", nil, "The target names to clean the outputs of (default all).")
+	cleanCmd.MarkFlagsMutuallyExclusive("git-ignored", "outputs")
+
 	cleanCmd.Flags().
 		BoolVarP(&args.force,
 			"force", "f", false, "Instead of doing a dry-run really clean it.")
@@ -64,6 +82,15 @@ func execute(cli cli.ICLI, c *cleanArgs) error {
 	for i := range comps {
 		comp := comps[i]
 
+		if c.outputs {
+			err = cleanOutputs(comp, c.targets, c.force)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		if c.gitIgnored {
 			if !c.force {
 				log.Info("Dry Run: Would clean with `git clean -X`.", "cwd", comp.Root())
@@ -95,3 +122,37 @@ func execute(cli cli.ICLI, c *cleanArgs) error {
 
 	return nil
 }
+
+// cleanOutputs removes the declared outputs of all targets
+// with names `targetNames` (or all if empty) on component `comp`.
+func cleanOutputs(comp *component.Component, targetNames []string, force bool) error {
+	names := slices.Sorted(maps.Keys(comp.Config().Targets))
+
+	for _, name := range names {
+		if len(targetNames) != 0 && !slices.Contains(targetNames, name) {
+			continue
+		}
+
+		t := comp.Config().Targets[name]
+		paths, _, err := dag.ResolveOutputPaths(comp, t.Outputs)
+		if err != nil {
+			return errors.AddContext(err, "could not resolve outputs of target '%v'", t.ID)
+		}
+
+		for _, p := range paths {
+			if !force {
+				log.Info("Dry Run: Would remove output.", "target", t.ID, "path", p)
+
+				continue
+			}
+
+			log.Info("Removing output.", "target", t.ID, "path", p)
+			err = os.RemoveAll(p)
+			if err != nil {
+				return errors.AddContext(err, "could not remove output '%v'", p)
+			}
+		}
+	}
+
+	return nil
+}
@@ -146,11 +146,13 @@ func runExec(cli cli.ICLI, args *toolchain.DispatchArgs) error {
 	}
 
 	return dag.ExecuteRunner(
+		cli.Ctx(),
 		log.Global(),
 		comp,
 		target.ID,
 		step.Index,
 		args.RunnerIndex,
+		args.DependencyOutputs,
 		runner.Runner,
 		runner.Toolchain,
 		dispatcher,
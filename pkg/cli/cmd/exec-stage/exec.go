@@ -8,6 +8,7 @@ import (
 	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
 	"github.com/sdsc-ordes/quitsh/pkg/dag"
 	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
 	"github.com/sdsc-ordes/quitsh/pkg/toolchain"
 
 	"github.com/spf13/cobra"
@@ -30,12 +31,13 @@ func AddCmdGeneral(
 	execArgs *dag.ExecArgs,
 ) {
 	var compArgs general.ComponentArgs
+	var changeArgs general.ChangeArgs
 	cmd := &cobra.Command{
 		Use:   "exec-stage [stage...]",
 		Short: "Execute all targets in a stage.",
 		RunE: func(_ *cobra.Command, stages []string) error {
 			for _, s := range stages {
-				e := ExecuteStage(cli, &compArgs, stage.Stage(s), execArgs)
+				e := ExecuteStage(cli, &compArgs, &changeArgs, stage.Stage(s), execArgs)
 				if e != nil {
 					return e
 				}
@@ -45,7 +47,9 @@ func AddCmdGeneral(
 		},
 	}
 	general.AddFlagsExecArgs(cmd, execArgs)
+	general.AddFlagsShardArgs(cmd, execArgs)
 	general.AddFlagsComponentArgs(cmd, &compArgs)
+	general.AddFlagsChangeArgs(cmd, &changeArgs)
 
 	parent.AddCommand(cmd)
 }
@@ -68,17 +72,20 @@ func AddCmdAlias(
 	}
 
 	var compArgs general.ComponentArgs
+	var changeArgs general.ChangeArgs
 
 	cmd := &cobra.Command{
 		Use:   o.name,
 		Short: fmt.Sprintf("Execute all targets in stage %v.", stage),
 		RunE: func(_ *cobra.Command, _args []string) error {
-			return ExecuteStage(cli, &compArgs, stage, execArgs)
+			return ExecuteStage(cli, &compArgs, &changeArgs, stage, execArgs)
 		},
 	}
 
 	general.AddFlagsExecArgs(cmd, execArgs)
+	general.AddFlagsShardArgs(cmd, execArgs)
 	general.AddFlagsComponentArgs(cmd, &compArgs)
+	general.AddFlagsChangeArgs(cmd, &changeArgs)
 
 	if o.modify != nil {
 		o.modify(cmd)
@@ -88,9 +95,12 @@ func AddCmdAlias(
 }
 
 // ExecuteStage executes all targets found with `compArgs` which belong to stage `stage`.
+// If `changeArgs` is given, only the targets affected by the changes (and their dependents)
+// are executed.
 func ExecuteStage(
 	cl cli.ICLI,
 	compArgs *general.ComponentArgs,
+	changeArgs *general.ChangeArgs,
 	stage stage.Stage,
 	execArgs *dag.ExecArgs,
 ) error {
@@ -99,13 +109,50 @@ func ExecuteStage(
 		return err
 	}
 
-	targets, prios, err := dag.DefineExecutionOrder(
-		all, rootDir,
-		dag.WithTargetsByStageFromComponents(comps, stage),
-	)
+	opts := []dag.ExecOption{dag.WithTargetsByStageFromComponents(comps, stage)}
+	if changeArgs != nil {
+		paths, e := changeArgs.Paths(rootDir)
+		if e != nil {
+			return e
+		} else if paths != nil {
+			opts = append(opts, dag.WithInputChanges(paths))
+		}
+	}
+
+	lastRunFile := dag.LastRunFile(cl.RootArgs().OutputDir(rootDir))
+	if execArgs.RerunFailed {
+		ids, e := dag.LoadRerunTargets(lastRunFile)
+		if e != nil {
+			return e
+		}
+		opts = append(opts, dag.WithTargetSelectionRestrict(ids...))
+	}
+
+	shardOpts, err := general.ShardOptions(execArgs)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, shardOpts...)
+
+	targets, prios, err := dag.DefineExecutionOrder(all, rootDir, opts...)
 	if err != nil {
 		return err
 	} else if len(targets) == 0 {
+		switch {
+		case execArgs.Shard != "":
+			log.Info("No targets in this shard.", "stage", stage, "shard", execArgs.Shard)
+
+			return nil
+		case execArgs.RerunFailed:
+			log.Info("No failed targets to rerun.", "stage", stage)
+
+			return nil
+		case changeArgs != nil && changeArgs.IsSet():
+			log.Info("No targets affected by the changes.", "stage", stage)
+
+			return nil
+		}
+
 		return errors.New("no targets selected")
 	}
 
@@ -123,6 +170,17 @@ func ExecuteStage(
 		rootDir,
 		cl.RootArgs().Parallel,
 		dag.WithTags(execArgs.Tags...),
+		dag.WithForce(execArgs.Force),
+		dag.WithCacheLocation(execArgs.Cache, execArgs.CacheReadOnly),
+		dag.WithReport(execArgs.Report),
+		dag.WithTrace(execArgs.Trace),
+		dag.WithLastRunFile(lastRunFile),
+		dag.WithJobs(cl.RootArgs().Jobs),
+		dag.WithContext(cl.Ctx()),
+		dag.WithFailFast(cl.RootArgs().FailFast),
+		dag.WithBufferedOutput(cl.RootArgs().BufferOutput),
+		dag.WithProgress(!cl.RootArgs().NoProgress),
+		dag.WithTimeout(cl.RootArgs().Timeout),
 	)
 }
 
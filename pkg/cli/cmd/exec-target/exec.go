@@ -14,6 +14,8 @@ import (
 
 type execTargetArgs struct {
 	TargetIDs []string
+
+	Changes general.ChangeArgs
 }
 
 func AddCmd(
@@ -33,9 +35,9 @@ func AddCmd(
 		},
 	}
 
-	execCmd.Flags().StringArrayVar(&execArgs.Tags, "tag", execArgs.Tags,
-		"The executable tags which will get matched against the "+
-			"`include.tagExpr` on a step to include/exclude steps.")
+	general.AddFlagsExecArgs(execCmd, execArgs)
+	general.AddFlagsShardArgs(execCmd, execArgs)
+	general.AddFlagsChangeArgs(execCmd, &args.Changes)
 
 	_ = execCmd.MarkFlagRequired("component-dir")
 
@@ -54,18 +56,52 @@ func runExec(cli cli.ICLI, args *execTargetArgs, execArgs *dag.ExecArgs) error {
 		return err
 	}
 
-	selection := set.NewUnorderedWithCap[target.ID](len(args.TargetIDs))
-	for i := range args.TargetIDs {
-		selection.Insert(target.ID(args.TargetIDs[i]))
+	var opts []dag.ExecOption
+	if len(args.TargetIDs) != 0 || !execArgs.RerunFailed {
+		selection := set.NewUnorderedWithCap[target.ID](len(args.TargetIDs))
+		for i := range args.TargetIDs {
+			selection.Insert(target.ID(args.TargetIDs[i]))
+		}
+		opts = append(opts, dag.WithTargetSelection(&selection))
 	}
 
-	targets, prios, err := dag.DefineExecutionOrder(
-		all,
-		rootDir,
-		dag.WithTargetSelection(&selection),
-	)
+	lastRunFile := dag.LastRunFile(cli.RootArgs().OutputDir(rootDir))
+	if execArgs.RerunFailed {
+		ids, e := dag.LoadRerunTargets(lastRunFile)
+		if e != nil {
+			return e
+		}
+		opts = append(opts, dag.WithTargetSelectionRestrict(ids...))
+	}
+
+	paths, err := args.Changes.Paths(rootDir)
+	if err != nil {
+		return err
+	} else if paths != nil {
+		opts = append(opts, dag.WithInputChanges(paths))
+	}
+
+	shardOpts, err := general.ShardOptions(execArgs)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, shardOpts...)
+
+	targets, prios, err := dag.DefineExecutionOrder(all, rootDir, opts...)
 	if err != nil {
 		return err
+	} else if len(targets) == 0 && execArgs.Shard != "" {
+		log.Info("No targets in this shard.", "shard", execArgs.Shard)
+
+		return nil
+	} else if len(targets) == 0 && execArgs.RerunFailed {
+		log.Info("No failed targets to rerun.")
+
+		return nil
+	} else if len(targets) == 0 && args.Changes.IsSet() {
+		log.Info("No targets affected by the changes.")
+
+		return nil
 	}
 
 	var dispatcher toolchain.IDispatcher
@@ -82,5 +118,16 @@ func runExec(cli cli.ICLI, args *execTargetArgs, execArgs *dag.ExecArgs) error {
 		rootDir,
 		cli.RootArgs().Parallel,
 		dag.WithTags(execArgs.Tags...),
+		dag.WithForce(execArgs.Force),
+		dag.WithCacheLocation(execArgs.Cache, execArgs.CacheReadOnly),
+		dag.WithReport(execArgs.Report),
+		dag.WithTrace(execArgs.Trace),
+		dag.WithLastRunFile(lastRunFile),
+		dag.WithJobs(cli.RootArgs().Jobs),
+		dag.WithContext(cli.Ctx()),
+		dag.WithFailFast(cli.RootArgs().FailFast),
+		dag.WithBufferedOutput(cli.RootArgs().BufferOutput),
+		dag.WithProgress(!cli.RootArgs().NoProgress),
+		dag.WithTimeout(cli.RootArgs().Timeout),
 	)
 }
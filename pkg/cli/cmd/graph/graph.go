@@ -0,0 +1,91 @@
+package graphcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sdsc-ordes/quitsh/pkg/cli"
+	"github.com/sdsc-ordes/quitsh/pkg/cli/general"
+	"github.com/sdsc-ordes/quitsh/pkg/dag"
+
+	"github.com/spf13/cobra"
+)
+
+const longDesc = `
+Export the target graph (DAG) in Graphviz DOT, Mermaid or JSON.
+
+Without any selection the full graph is exported. Otherwise the targets
+are selected either by the given target ids or by the components
+('--components', '--component-dir') and '--stage' as in 'exec-target'
+and 'exec-stage', together with all their dependencies.
+With '--changed-since' or '--changed-working-tree' changed targets are coloured.
+`
+
+type graphArgs struct {
+	selArgs    general.TargetSelectionArgs
+	format     string
+	outputFile string
+}
+
+// AddCmd adds the `graph` command to `parent`.
+func AddCmd(cl cli.ICLI, parent *cobra.Command) {
+	var args graphArgs
+
+	graphCmd := &cobra.Command{
+		Use:          "graph [target-ids...]",
+		Short:        "Export the target graph.",
+		Long:         longDesc,
+		SilenceUsage: true,
+		RunE: func(_cmd *cobra.Command, targs []string) error {
+			args.selArgs.TargetIDs = targs
+
+			return exportGraph(cl, &args)
+		},
+	}
+
+	general.AddFlagsTargetSelectionArgs(graphCmd, &args.selArgs)
+	graphCmd.Flags().
+		StringVarP(&args.format, "format", "f", string(dag.GraphFormatDot),
+			fmt.Sprintf("The output format, one of '%q'.", dag.GraphFormats))
+	graphCmd.Flags().
+		StringVarP(&args.outputFile, "output", "o", "-",
+			"Output the graph to this file (`-` = `stdout`).")
+
+	parent.AddCommand(graphCmd)
+}
+
+func exportGraph(cl cli.ICLI, args *graphArgs) error {
+	err := args.selArgs.Init()
+	if err != nil {
+		return err
+	}
+
+	comps, all, rootDir, err := cl.FindComponents(&args.selArgs.CompArgs)
+	if err != nil {
+		return err
+	}
+
+	execOpts, err := args.selArgs.ExecOptions(comps, rootDir)
+	if err != nil {
+		return err
+	}
+
+	nodes, _, err := dag.DefineExecutionOrder(all, rootDir, execOpts...)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if args.outputFile != "-" && args.outputFile != "" {
+		f, e := os.Create(args.outputFile)
+		if e != nil {
+			return e
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	return dag.ExportGraph(w, nodes, dag.GraphFormat(args.format))
+}
@@ -0,0 +1,100 @@
+package plancmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sdsc-ordes/quitsh/pkg/cli"
+	"github.com/sdsc-ordes/quitsh/pkg/cli/general"
+	"github.com/sdsc-ordes/quitsh/pkg/dag"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+
+	"github.com/spf13/cobra"
+)
+
+const longDesc = `
+Print the resolved execution plan without running anything.
+
+The targets are selected either by the given target ids or by
+the components ('--components', '--component-dir') and '--stage'
+as in 'exec-target' and 'exec-stage'.
+With '--changed-since' or '--changed-working-tree' only the targets
+whose inputs changed are selected.
+`
+
+type planArgs struct {
+	selArgs general.TargetSelectionArgs
+	tags    []string
+	json    bool
+}
+
+// AddCmd adds the `plan` command to `parent`.
+func AddCmd(cl cli.ICLI, parent *cobra.Command) {
+	var args planArgs
+
+	planCmd := &cobra.Command{
+		Use:          "plan [target-ids...]",
+		Short:        "Print the execution plan (dry-run).",
+		Long:         longDesc,
+		SilenceUsage: true,
+		RunE: func(_cmd *cobra.Command, targs []string) error {
+			args.selArgs.TargetIDs = targs
+
+			return printPlan(cl, &args)
+		},
+	}
+
+	general.AddFlagsTargetSelectionArgs(planCmd, &args.selArgs)
+	planCmd.Flags().
+		StringArrayVar(&args.tags, "tag", nil,
+			"The executable tags which will get matched against the "+
+				"`include.tagExpr` on a step to include/exclude steps.")
+	planCmd.Flags().
+		BoolVar(&args.json, "json", false, "Output the plan as JSON.")
+
+	parent.AddCommand(planCmd)
+}
+
+func printPlan(cl cli.ICLI, args *planArgs) error {
+	err := args.selArgs.Init()
+	if err != nil {
+		return err
+	}
+
+	comps, all, rootDir, err := cl.FindComponents(&args.selArgs.CompArgs)
+	if err != nil {
+		return err
+	}
+
+	execOpts, err := args.selArgs.ExecOptions(comps, rootDir)
+	if err != nil {
+		return err
+	}
+
+	_, prios, err := dag.DefineExecutionOrder(all, rootDir, execOpts...)
+	if err != nil {
+		return err
+	}
+
+	plan, err := dag.NewPlan(
+		prios,
+		cl.RunnerFactory(),
+		!cl.RootArgs().SkipToolchainDispatch,
+		dag.WithTags(args.tags...),
+	)
+	if err != nil {
+		return err
+	}
+
+	if args.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return errors.AddContext(enc.Encode(plan), "could not marshal plan to JSON")
+	}
+
+	_, err = fmt.Fprint(os.Stdout, plan.Format())
+
+	return err
+}
@@ -0,0 +1,139 @@
+package querycmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/sdsc-ordes/quitsh/pkg/cli"
+	"github.com/sdsc-ordes/quitsh/pkg/cli/general"
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/dag"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+
+	"github.com/spf13/cobra"
+)
+
+const longDesc = `
+Query the target graph with an expression and print the matching target ids.
+
+The expression is built from:
+  - '<glob>':            all targets whose id matches the glob (e.g. '*::test').
+  - 'deps(<expr>)':      all transitive dependencies of the targets in '<expr>'.
+  - 'rdeps(<expr>)':     all transitive dependents of the targets in '<expr>'.
+  - 'stage(<stage>)':    all targets in a stage.
+  - 'component(<glob>)': all targets of components whose name matches the glob.
+  - 'changed(<rev>)':    all targets changed since the merge-base of
+                         revision '<rev>' and 'HEAD' (also by dependencies).
+  - '<expr> & <expr>' (intersection), '<expr> | <expr>' (union) and
+    parentheses for grouping.
+
+Examples:
+  quitsh query 'rdeps(lib-x::build)'
+  quitsh query 'deps(*::test)'
+  quitsh query 'stage(test) & changed(origin/main)' --components
+`
+
+type queryArgs struct {
+	components bool
+	json       bool
+}
+
+// AddCmd adds the `query` command to `parent`.
+func AddCmd(cl cli.ICLI, parent *cobra.Command) {
+	var args queryArgs
+
+	queryCmd := &cobra.Command{
+		Use:          "query <expr>",
+		Short:        "Query the target graph.",
+		Long:         longDesc,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(_cmd *cobra.Command, qargs []string) error {
+			return runQuery(cl, qargs[0], &args)
+		},
+	}
+
+	queryCmd.Flags().
+		BoolVar(&args.components, "components", false,
+			"Print the names of the components of the matched targets instead.")
+	queryCmd.Flags().
+		BoolVar(&args.json, "json", false, "Output the result as JSON.")
+
+	parent.AddCommand(queryCmd)
+}
+
+func runQuery(cl cli.ICLI, expr string, args *queryArgs) error {
+	_, all, rootDir, err := cl.FindComponents(
+		&general.ComponentArgs{ComponentPatterns: []string{"*"}},
+	)
+	if err != nil {
+		return err
+	}
+
+	nodes, _, err := dag.DefineExecutionOrder(all, rootDir)
+	if err != nil {
+		return err
+	}
+
+	ids, err := dag.Query(nodes, expr, changedSince(all, rootDir))
+	if err != nil {
+		return err
+	}
+
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if args.components {
+			result = append(result, nodes[id].Comp.Name())
+		} else {
+			result = append(result, id.String())
+		}
+	}
+	slices.Sort(result)
+	result = slices.Compact(result)
+
+	if args.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return errors.AddContext(enc.Encode(result), "could not marshal result to JSON")
+	}
+
+	for _, r := range result {
+		_, err = fmt.Fprintln(os.Stdout, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// changedSince returns the function to determine all changed targets
+// since a revision.
+func changedSince(comps []*component.Component, rootDir string) dag.ChangedFunc {
+	return func(rev string) ([]target.ID, error) {
+		changeArgs := general.ChangeArgs{ChangedSince: rev}
+
+		paths, err := changeArgs.Paths(rootDir)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes, _, err := dag.DefineExecutionOrder(comps, rootDir, dag.WithInputChanges(paths))
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []target.ID
+		for id, n := range nodes {
+			if n.Inputs.IsChanged() {
+				ids = append(ids, id)
+			}
+		}
+
+		return ids, nil
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"runtime"
+	"time"
 
 	e "errors"
 
@@ -65,6 +68,23 @@ type (
 
 		// Enable running targets in parallel.
 		Parallel bool `yaml:"parallel"`
+		// The maximal number of runners executing at the same time
+		// when running in parallel. Defaults to the CPU count.
+		Jobs int `yaml:"jobs"`
+
+		// Buffer the output of each target and print it in one block
+		// when the target finished.
+		BufferOutput bool `yaml:"bufferOutput"`
+		// Disable the live progress view when running in parallel.
+		NoProgress bool `yaml:"noProgress"`
+
+		// Cancel all running and pending runners as soon as one fails.
+		FailFast bool `yaml:"failFast"`
+		// Keep executing all targets which do not depend on a failed one (default).
+		KeepGoing bool `yaml:"keepGoing"`
+
+		// The default timeout of targets which do not define one.
+		Timeout time.Duration `yaml:"timeout"`
 	}
 
 	Settings struct {
@@ -82,6 +102,20 @@ func (s *Args) SetDefaults() {
 	if s.ConfigUser == "" {
 		s.ConfigUser = os.Getenv(common.EnvQuitshConfigUser)
 	}
+	if s.Jobs == 0 {
+		s.Jobs = runtime.NumCPU()
+	}
+}
+
+// OutputDir returns the global output directory for the root directory `rootDir`.
+// It is `<root-dir>/.output` if no global output directory is given.
+func (s *Args) OutputDir(rootDir string) string {
+	base := rootDir
+	if s.GlobalOutputDir != "" {
+		base = fs.MakeAbsoluteTo(rootDir, s.GlobalOutputDir)
+	}
+
+	return path.Join(base, fs.OutputDir)
 }
 
 // SetDefaults implements [defaults.Setter].
@@ -183,6 +217,31 @@ func New(setts *Settings, rootArgs *Args, config config.IConfig) (
 	rootCmd.PersistentFlags().
 		BoolVarP(&rootArgs.Parallel,
 			"parallel", "P", rootArgs.Parallel, "If targets are built in parallel.")
+	rootCmd.PersistentFlags().
+		IntVarP(&rootArgs.Jobs,
+			"jobs", "j", rootArgs.Jobs,
+			"The maximal number of runners executing at the same time "+
+				"when building in parallel (default: CPU count).")
+	rootCmd.PersistentFlags().
+		BoolVar(&rootArgs.BufferOutput,
+			"buffer-output", rootArgs.BufferOutput,
+			"Buffer the output of each target and print it in one block when the target finished.")
+	rootCmd.PersistentFlags().
+		BoolVar(&rootArgs.NoProgress,
+			"no-progress", rootArgs.NoProgress,
+			"Disable the live progress view when building in parallel on a terminal.")
+	rootCmd.PersistentFlags().
+		BoolVar(&rootArgs.FailFast,
+			"fail-fast", rootArgs.FailFast,
+			"Cancel all running and pending runners as soon as one fails.")
+	rootCmd.PersistentFlags().
+		BoolVar(&rootArgs.KeepGoing,
+			"keep-going", rootArgs.KeepGoing,
+			"Keep executing all targets which do not depend on a failed one (default).")
+	rootCmd.PersistentFlags().
+		DurationVar(&rootArgs.Timeout,
+			"timeout", rootArgs.Timeout,
+			"The default timeout of targets which do not define one (e.g. '10m').")
 
 	rootCmd.Flags().
 		BoolVar(&version, "version", version, "Print the version.")
@@ -381,6 +440,18 @@ func applyGeneralOptions(r *Args) error {
 		}
 	}
 
+	if r.Jobs < 0 {
+		return errors.New("the number of jobs '%v' must be positive", r.Jobs)
+	}
+
+	if r.Timeout < 0 {
+		return errors.New("the timeout '%v' must be positive", r.Timeout)
+	}
+
+	if r.FailFast && r.KeepGoing {
+		return errors.New("either use '--fail-fast' or '--keep-going', but not both")
+	}
+
 	if r.GlobalOutput && r.GlobalOutputDir != "" {
 		return errors.New("either use '--global-output' or " +
 			"'--global-output-dir', but not both")
@@ -0,0 +1,270 @@
+package watchcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/cli"
+	"github.com/sdsc-ordes/quitsh/pkg/cli/general"
+	"github.com/sdsc-ordes/quitsh/pkg/common/set"
+	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/dag"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/filesystem/watch"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+	"github.com/sdsc-ordes/quitsh/pkg/toolchain"
+
+	"github.com/spf13/cobra"
+)
+
+const longDesc = `
+Execute the selected targets and watch the roots of all involved components
+for changes. On changes (debounced), only the targets affected by the changed
+paths (and their dependents) are executed again.
+
+The arguments are either a stage (e.g. 'build') or target ids (e.g. 'comp::build').
+A run in progress is cancelled when new changes arrive.
+Changes to the outputs of the selected targets are ignored.
+`
+
+type watchArgs struct {
+	compArgs general.ComponentArgs
+
+	debounce time.Duration
+	poll     time.Duration
+	clear    bool
+}
+
+// AddCmd adds the `watch` command to `parent`.
+func AddCmd(cl cli.ICLI, parent *cobra.Command, execArgs *dag.ExecArgs) {
+	var args watchArgs
+
+	watchCmd := &cobra.Command{
+		Use:          "watch <stage|target-ids...>",
+		Short:        "Execute targets again when their inputs change.",
+		Long:         longDesc,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(_cmd *cobra.Command, targs []string) error {
+			return runWatch(cl, targs, &args, execArgs)
+		},
+	}
+
+	general.AddFlagsExecArgs(watchCmd, execArgs)
+	watchCmd.Flags().
+		StringArrayVarP(&args.compArgs.ComponentPatterns,
+			"components", "c", []string{"*"}, "Components matched by these patterns are considered.")
+	watchCmd.Flags().
+		DurationVar(&args.debounce, "debounce", watch.DefaultDebounce,
+			"Time to wait for further changes before executing.")
+	watchCmd.Flags().
+		DurationVar(&args.poll, "poll", 0,
+			"Use polling with this interval instead of native file system events.")
+	watchCmd.Flags().
+		BoolVar(&args.clear, "clear", false,
+			"Clear the terminal before each run.")
+
+	parent.AddCommand(watchCmd)
+}
+
+func runWatch(cl cli.ICLI, targs []string, args *watchArgs, execArgs *dag.ExecArgs) error {
+	if execArgs.RerunFailed {
+		return errors.New("'--rerun-failed' is not supported when watching")
+	}
+
+	ctx := cl.Ctx()
+
+	// The first run executes all selected targets.
+	targets, err := executeRun(ctx, cl, targs, args, execArgs, 1, nil)
+	if targets == nil && err != nil {
+		return err
+	} else if len(targets) == 0 {
+		return errors.New("no targets selected")
+	} else if err != nil && ctx.Err() == nil {
+		log.ErrorE(err, "Run failed.", "run", 1)
+	}
+
+	roots := set.NewUnordered[string]()
+	for _, n := range targets {
+		roots.Insert(n.Comp.Root())
+	}
+	dirs := slices.Sorted(roots.Keys())
+
+	ignored := fs.IgnoredDirectoriesDefault()
+
+	var opts []watch.Option
+	opts = append(opts,
+		watch.WithDebounce(args.debounce),
+		watch.WithExclude(func(dir string) bool {
+			return slices.Contains(ignored, path.Base(dir))
+		}))
+	if args.poll != 0 {
+		opts = append(opts, watch.WithPolling(args.poll))
+	}
+
+	batches, err := watch.Watch(ctx, dirs, opts...)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Watching for changes ...", "dirs", dirs)
+
+	var (
+		runCancel context.CancelFunc
+		runDone   chan bool
+		pending   []string
+		runIdx    = 1
+	)
+
+	// Cancel a run in progress and return if it was cancelled.
+	stopRun := func() (cancelled bool) {
+		if runDone == nil {
+			return false
+		}
+		runCancel()
+		cancelled = <-runDone
+		runDone = nil
+
+		return cancelled
+	}
+	defer stopRun()
+
+	for batch := range batches {
+		// Outputs written by the targets themselves (e.g. relative to the
+		// component root) must not trigger another run.
+		batch = dag.DropOutputChanges(targets, batch)
+		if len(batch) == 0 {
+			log.Debug("Only target outputs changed.")
+
+			continue
+		}
+
+		if stopRun() {
+			// Changes of a cancelled run need to be considered again.
+			batch = append(pending, batch...)
+			slices.Sort(batch)
+			batch = slices.Compact(batch)
+		}
+		pending = batch
+		runIdx++
+
+		runCtx, cancel := context.WithCancel(ctx)
+		runCancel = cancel
+		runDone = make(chan bool, 1)
+
+		go func(idx int, done chan<- bool) {
+			defer cancel()
+
+			_, e := executeRun(runCtx, cl, targs, args, execArgs, idx, batch)
+			if e != nil && runCtx.Err() == nil {
+				log.ErrorE(e, "Run failed.", "run", idx)
+			}
+			done <- runCtx.Err() != nil
+		}(runIdx, runDone)
+	}
+
+	return nil
+}
+
+// executeRun executes the targets selected by `targs` which are affected
+// by the changed paths `changes`. If `changes` is `nil`, all selected targets are executed.
+// It returns all nodes which were considered for execution.
+func executeRun(
+	ctx context.Context,
+	cl cli.ICLI,
+	targs []string,
+	args *watchArgs,
+	execArgs *dag.ExecArgs,
+	runIdx int,
+	changes []string,
+) (dag.TargetNodeMap, error) {
+	if args.clear {
+		fmt.Fprint(os.Stdout, "\033[H\033[2J")
+	}
+	log.Info(
+		fmt.Sprintf("━━━ Run #%v ━━━", runIdx),
+		"changed-paths", len(changes),
+		"time", time.Now().Format(time.TimeOnly),
+	)
+
+	comps, all, rootDir, err := cl.FindComponents(&args.compArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []dag.ExecOption
+	if isTargetIDs(targs) {
+		selection := set.NewUnorderedWithCap[target.ID](len(targs))
+		for i := range targs {
+			selection.Insert(target.ID(targs[i]))
+		}
+		opts = append(opts, dag.WithTargetSelection(&selection))
+	} else {
+		for _, s := range targs {
+			opts = append(opts, dag.WithTargetsByStageFromComponents(comps, stage.Stage(s)))
+		}
+	}
+
+	if changes != nil {
+		opts = append(opts, dag.WithInputChanges(changes))
+	}
+
+	targets, prios, err := dag.DefineExecutionOrder(all, rootDir, opts...)
+	if err != nil {
+		return nil, err
+	} else if len(targets) == 0 {
+		if changes != nil {
+			log.Info("No targets affected by the changes.")
+		}
+
+		return targets, nil
+	}
+
+	var dispatcher toolchain.IDispatcher
+	if !cl.RootArgs().SkipToolchainDispatch {
+		dispatcher = cl.ToolchainDispatcher()
+	}
+
+	err = dag.Execute(
+		targets,
+		prios,
+		cl.RunnerFactory(),
+		dispatcher,
+		cl.Config(),
+		rootDir,
+		cl.RootArgs().Parallel,
+		dag.WithTags(execArgs.Tags...),
+		dag.WithForce(execArgs.Force),
+		dag.WithCacheLocation(execArgs.Cache, execArgs.CacheReadOnly),
+		dag.WithReport(execArgs.Report),
+		dag.WithTrace(execArgs.Trace),
+		dag.WithJobs(cl.RootArgs().Jobs),
+		dag.WithContext(ctx),
+		dag.WithFailFast(cl.RootArgs().FailFast),
+		dag.WithBufferedOutput(cl.RootArgs().BufferOutput),
+		dag.WithProgress(!cl.RootArgs().NoProgress),
+		dag.WithTimeout(cl.RootArgs().Timeout),
+	)
+
+	if ctx.Err() != nil {
+		log.Warn("Run cancelled.", "run", runIdx)
+	} else if err == nil {
+		log.Info("Run succeeded. Waiting for changes ...", "run", runIdx)
+	}
+
+	return targets, err
+}
+
+// isTargetIDs reports if the arguments are target ids instead of stages.
+func isTargetIDs(targs []string) bool {
+	return slices.ContainsFunc(targs, func(s string) bool {
+		return strings.Contains(s, "::")
+	})
+}
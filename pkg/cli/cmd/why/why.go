@@ -0,0 +1,99 @@
+package whycmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sdsc-ordes/quitsh/pkg/cli"
+	"github.com/sdsc-ordes/quitsh/pkg/cli/general"
+	"github.com/sdsc-ordes/quitsh/pkg/common/set"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/dag"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+
+	"github.com/spf13/cobra"
+)
+
+const longDesc = `
+Explain why a target is considered changed given the changed paths
+from '--changed-since' or '--changed-working-tree'.
+
+A target is changed either by its own inputs (the input id, pattern and path
+which matched are shown) or by a dependency (the chain of upstream targets
+which carried the change is shown).
+`
+
+type whyArgs struct {
+	changeArgs general.ChangeArgs
+	json       bool
+}
+
+// AddCmd adds the `why` command to `parent`.
+func AddCmd(cl cli.ICLI, parent *cobra.Command) {
+	var args whyArgs
+
+	whyCmd := &cobra.Command{
+		Use:          "why <target-id>",
+		Short:        "Explain why a target is considered changed.",
+		Long:         longDesc,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(_cmd *cobra.Command, targs []string) error {
+			return explain(cl, target.ID(targs[0]), &args)
+		},
+	}
+
+	general.AddFlagsChangeArgs(whyCmd, &args.changeArgs)
+	whyCmd.Flags().
+		BoolVar(&args.json, "json", false, "Output the explanation as JSON.")
+
+	parent.AddCommand(whyCmd)
+}
+
+func explain(cl cli.ICLI, id target.ID, args *whyArgs) error {
+	if !args.changeArgs.IsSet() {
+		return errors.New(
+			"no changed paths given, use '--changed-since' or '--changed-working-tree'")
+	}
+
+	_, all, rootDir, err := cl.FindComponents(
+		&general.ComponentArgs{ComponentPatterns: []string{"*"}},
+	)
+	if err != nil {
+		return err
+	}
+
+	paths, err := args.changeArgs.Paths(rootDir)
+	if err != nil {
+		return err
+	}
+
+	selection := set.NewUnordered(id)
+	targets, _, err := dag.DefineExecutionOrder(
+		all,
+		rootDir,
+		dag.WithTargetSelection(&selection),
+		dag.WithInputChanges(paths),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Unchanged targets are not in the selection anymore.
+	e := dag.Explanation{ID: id}
+	if n, exists := targets[id]; exists {
+		e = dag.Explain(n)
+	}
+
+	if args.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return errors.AddContext(enc.Encode(e), "could not marshal explanation to JSON")
+	}
+
+	_, err = fmt.Fprint(os.Stdout, e.Format())
+
+	return err
+}
@@ -0,0 +1,88 @@
+package general
+
+import (
+	"slices"
+
+	"github.com/sdsc-ordes/quitsh/pkg/exec/git"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+// ChangeArgs define the changed paths to only select affected targets
+// (see [dag.WithInputChanges]).
+type ChangeArgs struct {
+	// Take the changed paths between the merge-base of this revision and `HEAD`.
+	ChangedSince string
+
+	// Take the changed paths of the uncommitted changes in the working tree.
+	ChangedWorkingTree bool
+}
+
+// AddFlagsChangeArgs adds the flags to command `cmd`
+// for an instance of [ChangeArgs].
+func AddFlagsChangeArgs(cmd *cobra.Command, args *ChangeArgs) {
+	cmd.Flags().
+		StringVar(&args.ChangedSince,
+			"changed-since", "",
+			"Only select targets whose inputs changed since the merge-base of this revision and 'HEAD' "+
+				"(e.g. 'origin/main').")
+	cmd.Flags().
+		BoolVar(&args.ChangedWorkingTree,
+			"changed-working-tree", false,
+			"Only select targets whose inputs changed by the uncommitted changes in the working tree.")
+}
+
+// IsSet returns `true` if any changes are given.
+func (a *ChangeArgs) IsSet() bool {
+	return a.ChangedSince != "" || a.ChangedWorkingTree
+}
+
+// Paths returns all changed paths combined over all arguments.
+// The Git changes are computed in the repository at `rootDir` and
+// are absolute. If no changes are given, `nil` is returned
+// which means everything is changed.
+func (a *ChangeArgs) Paths(rootDir string) ([]string, error) {
+	if !a.IsSet() {
+		return nil, nil
+	}
+
+	// Note: Must not be `nil` even if nothing changed.
+	paths := []string{}
+
+	gitx := git.NewCtx(rootDir)
+	repoDir, err := gitx.RootDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if a.ChangedSince != "" {
+		base, e := gitx.MergeBase(a.ChangedSince, "HEAD")
+		if e != nil {
+			return nil, e
+		}
+
+		changes, e := gitx.ChangesBetweenRevs(repoDir, base, "HEAD", true)
+		if e != nil {
+			return nil, e
+		}
+
+		log.Info("Changes since merge-base.",
+			"rev", a.ChangedSince, "merge-base", base, "count", len(changes))
+		paths = append(paths, fs.MakeAllAbsoluteTo(repoDir, changes...)...)
+	}
+
+	if a.ChangedWorkingTree {
+		changes, e := gitx.Changes(repoDir, true)
+		if e != nil {
+			return nil, e
+		}
+
+		log.Info("Changes in working tree.", "count", len(changes))
+		paths = append(paths, fs.MakeAllAbsoluteTo(repoDir, changes...)...)
+	}
+
+	slices.Sort(paths)
+
+	return slices.Compact(paths), nil
+}
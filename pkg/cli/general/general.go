@@ -1,8 +1,11 @@
 package general
 
 import (
+	"time"
+
 	"github.com/sdsc-ordes/quitsh/pkg/component"
 	"github.com/sdsc-ordes/quitsh/pkg/component/query"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
 	"github.com/sdsc-ordes/quitsh/pkg/dag"
 	"github.com/sdsc-ordes/quitsh/pkg/errors"
 	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
@@ -40,6 +43,60 @@ func AddFlagsExecArgs(cmd *cobra.Command, execArgs *dag.ExecArgs) {
 	cmd.Flags().StringArrayVar(&execArgs.Tags, "tag", execArgs.Tags,
 		"The executable tags which will get matched against the "+
 			"`include.tagExpr` on a step to include/exclude steps.")
+	cmd.Flags().BoolVar(&execArgs.Force, "force", execArgs.Force,
+		"Run all targets even if they are up-to-date "+
+			"(fingerprint matches the last successful run).")
+	cmd.Flags().StringVar(&execArgs.Cache, "cache", execArgs.Cache,
+		"The shared output cache to restore target outputs from and upload them to "+
+			"(a directory, 'file://...' or 'http(s)://...').")
+	cmd.Flags().BoolVar(&execArgs.CacheReadOnly, "cache-read-only", execArgs.CacheReadOnly,
+		"Only restore target outputs from the cache, never upload them.")
+	cmd.Flags().StringVar(&execArgs.Report, "report", execArgs.Report,
+		"Write a report of all runners to this file: "+
+			"JUnit XML ('.xml') or JSON ('.json').")
+	cmd.Flags().StringVar(&execArgs.Trace, "trace", execArgs.Trace,
+		"Write a Chrome trace event file of all runners to this file "+
+			"(viewable in Perfetto or 'chrome://tracing').")
+	cmd.Flags().BoolVar(&execArgs.RerunFailed, "rerun-failed", execArgs.RerunFailed,
+		"Only execute the failed, not run and cancelled targets of the last run "+
+			"(together with their dependencies).")
+}
+
+// AddFlagsShardArgs adds the sharding arguments of `execArgs` to the command.
+func AddFlagsShardArgs(cmd *cobra.Command, execArgs *dag.ExecArgs) {
+	cmd.Flags().StringVar(&execArgs.Shard, "shard", execArgs.Shard,
+		"Only execute the shard 'k/n' of the selected targets "+
+			"(together with their dependencies), e.g. one per CI job.")
+	cmd.Flags().StringVar(&execArgs.ShardDurations, "shard-durations", execArgs.ShardDurations,
+		"A JSON report (see '--report') of an earlier run to balance "+
+			"the shards by the recorded durations instead of the target count.")
+}
+
+// ShardOptions returns the options to only execute the shard given in `execArgs`.
+// Returns `nil` if no shard is given.
+func ShardOptions(execArgs *dag.ExecArgs) ([]dag.ExecOption, error) {
+	if execArgs.Shard == "" {
+		if execArgs.ShardDurations != "" {
+			return nil, errors.New("'--shard-durations' can only be used with '--shard'")
+		}
+
+		return nil, nil
+	}
+
+	shard, err := dag.ParseShard(execArgs.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	var durations map[target.ID]time.Duration
+	if execArgs.ShardDurations != "" {
+		durations, err = dag.LoadShardDurations(execArgs.ShardDurations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []dag.ExecOption{dag.WithShard(shard, durations)}, nil
 }
 
 // FindComponents dispatches to the query function to find all components and
@@ -0,0 +1,89 @@
+package general
+
+import (
+	"github.com/sdsc-ordes/quitsh/pkg/common/set"
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/dag"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// TargetSelectionArgs select targets either by ids (as `exec-target`) or
+// by components and stage (as `exec-stage`).
+type TargetSelectionArgs struct {
+	CompArgs ComponentArgs
+
+	// The target ids to select (exclusive to `CompArgs` and `Stage`).
+	TargetIDs []string
+
+	// Only select targets in this stage (all if empty).
+	Stage string
+
+	// Only select targets whose inputs changed.
+	Changes ChangeArgs
+}
+
+// AddFlagsTargetSelectionArgs adds the flags to command `cmd`
+// for an instance of [TargetSelectionArgs]. The target ids are
+// the positional arguments and need to be set separately.
+func AddFlagsTargetSelectionArgs(cmd *cobra.Command, args *TargetSelectionArgs) {
+	cmd.Flags().
+		StringArrayVarP(&args.CompArgs.ComponentPatterns,
+			"components", "c", nil, "Targets of components matched by these patterns are selected.")
+	cmd.Flags().
+		StringVar(&args.CompArgs.ComponentDir,
+			"component-dir", "", "Directory pointing to a component to select, instead of giving them by patterns.")
+	cmd.MarkFlagsMutuallyExclusive("components", "component-dir")
+
+	cmd.Flags().
+		StringVarP(&args.Stage,
+			"stage", "s", "", "Only select the targets in this stage.")
+
+	AddFlagsChangeArgs(cmd, &args.Changes)
+}
+
+// Init validates the selection and defaults the component patterns to all components.
+func (a *TargetSelectionArgs) Init() error {
+	if len(a.TargetIDs) != 0 &&
+		(a.Stage != "" || len(a.CompArgs.ComponentPatterns) != 0 ||
+			a.CompArgs.ComponentDir != "") {
+		return errors.New("either give target ids or select by components and stage, but not both")
+	}
+
+	if a.CompArgs.ComponentDir == "" && len(a.CompArgs.ComponentPatterns) == 0 {
+		a.CompArgs.ComponentPatterns = []string{"*"}
+	}
+
+	return nil
+}
+
+// ExecOptions returns the options for [dag.DefineExecutionOrder]
+// given the selected components `comps` in the repository at `rootDir`.
+func (a *TargetSelectionArgs) ExecOptions(
+	comps []*component.Component,
+	rootDir string,
+) ([]dag.ExecOption, error) {
+	var opts []dag.ExecOption
+
+	paths, err := a.Changes.Paths(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if paths != nil {
+		opts = append(opts, dag.WithInputChanges(paths))
+	}
+
+	if len(a.TargetIDs) != 0 {
+		selection := set.NewUnorderedWithCap[target.ID](len(a.TargetIDs))
+		for i := range a.TargetIDs {
+			selection.Insert(target.ID(a.TargetIDs[i]))
+		}
+		opts = append(opts, dag.WithTargetSelection(&selection))
+	} else {
+		opts = append(opts, dag.WithTargetsByStageFromComponents(comps, stage.Stage(a.Stage)))
+	}
+
+	return opts, nil
+}
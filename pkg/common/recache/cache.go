@@ -76,13 +76,18 @@ func (r *Cache) Add(pattern string) (*regexp.Regexp, error) {
 
 // Match returns `true` if any regex matches for `s`.
 func (r List) Match(s string) bool {
+	return r.Find(s) != nil
+}
+
+// Find returns the first regex which matches `s` or `nil`.
+func (r List) Find(s string) *regexp.Regexp {
 	for idx := range r {
 		if r[idx].MatchString(s) {
 			log.Debug("Regex matches.", "regex", r[idx].String())
 
-			return true
+			return r[idx]
 		}
 	}
 
-	return false
+	return nil
 }
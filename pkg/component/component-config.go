@@ -1,6 +1,8 @@
 package component
 
 import (
+	"maps"
+
 	"github.com/hashicorp/go-version"
 	"github.com/sdsc-ordes/quitsh/pkg/common"
 	"github.com/sdsc-ordes/quitsh/pkg/component/input"
@@ -26,6 +28,9 @@ type Config struct {
 func (c *Config) Init() (err error) {
 	err = common.Validator().Struct(c)
 
+	// Expand matrix targets.
+	err = errors.Combine(err, c.expandMatrixTargets())
+
 	// Init target.
 	for targetName, t := range c.Targets {
 		e := t.Init(target.DefineID(c.Name, targetName))
@@ -48,6 +53,37 @@ func (c *Config) Init() (err error) {
 	return
 }
 
+// expandMatrixTargets replaces all targets with a matrix by their expanded variants.
+func (c *Config) expandMatrixTargets() (err error) {
+	expanded := make(map[string]*target.Config)
+
+	for name, t := range c.Targets {
+		variants, e := t.ExpandMatrix(name)
+		if e != nil {
+			err = errors.Combine(err, e)
+
+			continue
+		} else if variants == nil {
+			continue
+		}
+
+		delete(c.Targets, name)
+		maps.Copy(expanded, variants)
+	}
+
+	for name, t := range expanded {
+		if _, exists := c.Targets[name]; exists {
+			err = errors.Combine(err,
+				errors.New("expanded matrix target '%v' already exists", name))
+
+			continue
+		}
+		c.Targets[name] = t
+	}
+
+	return err
+}
+
 // TargetByID finds the target by the respective name in the config.
 func (c *Config) TargetByID(id target.ID) *target.Config {
 	for _, t := range c.Targets {
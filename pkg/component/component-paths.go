@@ -73,6 +73,16 @@ func (c *Component) OutCIDir(p ...string) string {
 	return c.RelOutPath(fs.OutCIDir, p...)
 }
 
+// OutFingerprintDir returns the directory of the target fingerprints.
+func (c *Component) OutFingerprintDir(p ...string) string {
+	return c.RelOutPath(fs.OutFingerprintDir, p...)
+}
+
+// OutLogsDir returns the directory of the captured runner logs.
+func (c *Component) OutLogsDir(p ...string) string {
+	return c.RelOutPath(fs.OutLogsDir, p...)
+}
+
 // DocsDir returns the directory of the components docs folder.
 func (c *Component) DocsDir(p ...string) string {
 	return c.RelPath(fs.DocsDir, p...)
@@ -1,6 +1,8 @@
 package step
 
 import (
+	"time"
+
 	"github.com/sdsc-ordes/quitsh/pkg/errors"
 	"github.com/sdsc-ordes/quitsh/pkg/tags"
 )
@@ -23,6 +25,18 @@ type (
 		// The toolchain to use for the runner can be overridden.
 		Toolchain string `yaml:"toolchain"`
 
+		// The maximal duration each runner of this step may take.
+		Timeout time.Duration `yaml:"timeout,omitempty"`
+
+		// The retry policy for flaky runners of this step.
+		Retry Retry `yaml:"retry,omitempty"`
+
+		// Always run this step, even if earlier steps of the target failed,
+		// e.g. to stop services, collect logs or upload coverage.
+		// It also runs after the target timed out (only its own timeout applies).
+		// It is not run if the target did not start or the execution is cancelled.
+		Always bool `yaml:"always,omitempty"`
+
 		// The (optional) raw runner config, before unmarshalling.
 		ConfigRaw AuxConfigRaw `yaml:"config,omitempty"`
 	}
@@ -38,6 +52,10 @@ type (
 
 	AuxConfigRaw struct {
 		Unmarshal func(any) error `yaml:"-"`
+
+		// The generic decoded value of the config,
+		// used to detect changes in the config.
+		value any
 	}
 
 	// AuxConfig is the unmarshalled additional config,
@@ -61,6 +79,14 @@ func (c *Config) Init(idx Index) (err error) {
 		)
 	}
 
+	if c.Timeout < 0 {
+		err = errors.Combine(err, errors.New("step timeout must not be negative"))
+	}
+
+	if e := c.Retry.Validate(); e != nil {
+		err = errors.Combine(err, e)
+	}
+
 	return
 }
 
@@ -68,5 +94,11 @@ func (s *AuxConfigRaw) UnmarshalYAML(unmarshal func(any) error) error {
 	// Save the unmarshal function for later use.
 	s.Unmarshal = unmarshal
 
-	return nil
+	return unmarshal(&s.value)
+}
+
+// Value returns the generic decoded value of the raw config
+// (`nil` if not decoded from YAML).
+func (s *AuxConfigRaw) Value() any {
+	return s.value
 }
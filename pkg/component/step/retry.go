@@ -0,0 +1,52 @@
+package step
+
+import (
+	"slices"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+// Retry is the retry policy of a step.
+type Retry struct {
+	// The maximal number of attempts (including the first one).
+	// Values `<= 1` disable retrying.
+	Attempts int `yaml:"attempts"`
+
+	// The time to wait between attempts.
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+
+	// Only retry if the runner failed with one of these command exit codes.
+	// If empty, any failure is retried.
+	OnExitCodes []int `yaml:"onExitCodes,omitempty"`
+}
+
+// Validate validates the retry policy.
+func (r *Retry) Validate() (err error) {
+	if r.Attempts < 0 {
+		err = errors.Combine(err,
+			errors.New("retry attempts '%v' must not be negative", r.Attempts))
+	}
+
+	if r.Backoff < 0 {
+		err = errors.Combine(err,
+			errors.New("retry backoff '%v' must not be negative", r.Backoff))
+	}
+
+	return
+}
+
+// MaxAttempts returns the maximal number of attempts (at least 1).
+func (r *Retry) MaxAttempts() int {
+	return max(r.Attempts, 1)
+}
+
+// Matches tells if a failure with exit code `exitCode` should be retried.
+// The exit code is only known if `hasExitCode` is set.
+func (r *Retry) Matches(exitCode int, hasExitCode bool) bool {
+	if len(r.OnExitCodes) == 0 {
+		return true
+	}
+
+	return hasExitCode && slices.Contains(r.OnExitCodes, exitCode)
+}
@@ -2,6 +2,7 @@ package target
 
 import (
 	"strings"
+	"time"
 
 	"github.com/sdsc-ordes/quitsh/pkg/component/input"
 	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
@@ -17,16 +18,43 @@ type Config struct {
 
 	Steps []step.Config `yaml:"steps"`
 
+	// Run all steps of this target concurrently instead of one after another.
+	// Steps with `always` run after all other steps.
+	Parallel bool `yaml:"parallel,omitempty"`
+
 	Inputs       []input.ID `yaml:"inputs,omitempty"`
 	Dependencies []ID       `yaml:"depends,omitempty"`
 
+	// After is a weak dependency ordering: This target runs strictly after
+	// the targets with these ids, but only if they are selected as well.
+	// In contrast to `Dependencies` they are never pulled into the selection.
+	After []ID `yaml:"after,omitempty"`
+
+	// Outputs are the paths this target produces (see [Output]).
+	// Only targets with outputs are cached.
+	Outputs []Output `yaml:"outputs,omitempty"`
+
+	// Named locks this target holds while executing.
+	// Targets sharing a lock never execute at the same time,
+	// e.g. because they use the same database or port.
+	Locks []string `yaml:"locks,omitempty"`
+
+	// The maximal duration all steps of this target may take together.
+	// It counts from the start of the target, i.e. after its locks are acquired,
+	// including the time its steps wait for job slots or retries.
+	// Zero uses the global default timeout (if any).
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
 	// Custom tags (currently not used for quitsh, but for user-tooling)
 	Tags []string `yaml:"tags,omitempty"`
 
-	// TODO: Maybe we want here a `After` list which boils down
-	// to a weak dependency ordering.
-	// The after ids come strictly after this one, and are only used for
-	// priority computing and for when running the DAG.
+	// Matrix expands this target into one target per combination of
+	// parameter values, e.g. `build` with `buildType: [debug, release]` into
+	// `build[buildType=debug]` and `build[buildType=release]`.
+	Matrix Matrix `yaml:"matrix,omitempty"`
+
+	// The parameters of an expanded matrix target.
+	Parameters Parameters `yaml:"-"`
 }
 
 // Init initializes this config.
@@ -42,6 +70,26 @@ func (c *Config) Init(id ID) (err error) {
 		}
 	}
 
+	if c.Timeout < 0 {
+		err = errors.Combine(err,
+			errors.New("target config with id '%v' has a negative timeout", c.ID))
+	}
+
+	for _, l := range c.Locks {
+		if strings.TrimSpace(l) == "" {
+			err = errors.Combine(err,
+				errors.New("target config with id '%v' has an empty lock name", c.ID))
+		}
+	}
+
+	for i := range c.Outputs {
+		e := c.Outputs[i].Validate()
+		if e != nil {
+			e = errors.AddContext(e, "could not initialize target config with id '%v'", c.ID)
+			err = errors.Combine(err, e)
+		}
+	}
+
 	return
 }
 
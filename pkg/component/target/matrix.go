@@ -0,0 +1,214 @@
+package target
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+type (
+	// Matrix maps parameter names to their values.
+	// A target with a matrix is expanded into one target per combination
+	// of parameter values.
+	Matrix map[string][]string
+
+	// Parameters are the parameter values of one expanded matrix target.
+	Parameters map[string]string
+)
+
+var matrixNameRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// Validate validates the matrix.
+func (m Matrix) Validate() (err error) {
+	for name, values := range m {
+		if !matrixNameRe.MatchString(name) {
+			err = errors.Combine(err,
+				errors.New("matrix parameter name '%v' must match '%v'", name, matrixNameRe))
+		}
+
+		if len(values) == 0 {
+			err = errors.Combine(err,
+				errors.New("matrix parameter '%v' has no values", name))
+		}
+
+		for i, v := range values {
+			if v == "" || strings.ContainsAny(v, "[]=,") {
+				err = errors.Combine(err,
+					errors.New("matrix parameter '%v' has an invalid value '%v' "+
+						"(must be non-empty and not contain '[]=,')", name, v))
+			} else if slices.Contains(values[:i], v) {
+				err = errors.Combine(err,
+					errors.New("matrix parameter '%v' has a duplicate value '%v'", name, v))
+			}
+		}
+	}
+
+	return
+}
+
+// Expand returns all combinations of parameter values.
+// The order is deterministic: parameters sorted by name,
+// values in the given order.
+func (m Matrix) Expand() []Parameters {
+	if len(m) == 0 {
+		return nil
+	}
+
+	combs := []Parameters{{}}
+	for _, name := range slices.Sorted(maps.Keys(m)) {
+		next := make([]Parameters, 0, len(combs)*len(m[name]))
+
+		for _, c := range combs {
+			for _, v := range m[name] {
+				p := maps.Clone(c)
+				p[name] = v
+				next = append(next, p)
+			}
+		}
+
+		combs = next
+	}
+
+	return combs
+}
+
+// Format formats the parameters as `[a=1,b=2]` (sorted by name).
+func (p Parameters) Format() string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i, name := range slices.Sorted(maps.Keys(p)) {
+		if i != 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(name + "=" + p[name])
+	}
+	sb.WriteString("]")
+
+	return sb.String()
+}
+
+// Matches tells if all parameters in `subset` have the same value in `p`.
+func (p Parameters) Matches(subset Parameters) bool {
+	for name, v := range subset {
+		if pv, exists := p[name]; !exists || pv != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matrixParamRef returns the reference `${<name>}` to parameter `name`.
+func matrixParamRef(name string) string {
+	return "${" + name + "}"
+}
+
+// Expand replaces all parameter references `${<name>}` with
+// the values of the parameters `params`.
+func (o Output) Expand(params Parameters) Output {
+	s := string(o)
+	for name, v := range params {
+		s = strings.ReplaceAll(s, matrixParamRef(name), v)
+	}
+
+	return Output(s)
+}
+
+// DefineMatrixName defines the name of the expanded matrix target
+// `name` with parameters `params`, e.g. `build[buildType=release]`.
+func DefineMatrixName(name string, params Parameters) string {
+	return name + params.Format()
+}
+
+// SplitParameters splits an expanded matrix target id
+// `comp::build[a=1,b=2]` into its base id `comp::build` and its parameters.
+// Ids without (valid) parameters are returned unchanged with `nil` parameters.
+func (i *ID) SplitParameters() (ID, Parameters) {
+	s := string(*i)
+
+	idx := strings.LastIndex(s, "[")
+	if idx <= 0 || !strings.HasSuffix(s, "]") {
+		return *i, nil
+	}
+
+	params := Parameters{}
+	for kv := range strings.SplitSeq(s[idx+1:len(s)-1], ",") {
+		name, v, found := strings.Cut(kv, "=")
+		if !found || name == "" {
+			return *i, nil
+		}
+		params[name] = v
+	}
+
+	return ID(s[:idx]), params
+}
+
+// ExpandMatrix expands this (not yet initialized) target config with name `name`
+// into one config per combination of matrix parameters, keyed by
+// their names (see [DefineMatrixName]).
+// The outputs must contain all parameters as `${<name>}` (see [Output.Expand]),
+// such that no two variants produce the same paths.
+// Returns `nil` if this config has no matrix.
+func (c *Config) ExpandMatrix(name string) (map[string]*Config, error) {
+	if len(c.Matrix) == 0 {
+		return nil, nil
+	}
+
+	err := c.Matrix.Validate()
+	if err != nil {
+		return nil, errors.AddContext(err, "invalid matrix on target '%v'", name)
+	}
+
+	for _, o := range c.Outputs {
+		var missing []string
+		for _, n := range slices.Sorted(maps.Keys(c.Matrix)) {
+			if !strings.Contains(string(o), matrixParamRef(n)) {
+				missing = append(missing, matrixParamRef(n))
+			}
+		}
+
+		if len(missing) != 0 {
+			return nil, errors.New(
+				"output '%v' on matrix target '%v' must contain the parameters %q "+
+					"to be unique for each variant", o, name, missing)
+		}
+	}
+
+	combs := c.Matrix.Expand()
+	variants := make(map[string]*Config, len(combs))
+
+	for _, params := range combs {
+		v := *c
+		v.Matrix = nil
+		v.Parameters = params
+
+		// Every variant gets its own slices, since they are modified
+		// on resolving ids.
+		v.Steps = slices.Clone(c.Steps)
+		v.Inputs = slices.Clone(c.Inputs)
+		v.Dependencies = slices.Clone(c.Dependencies)
+		v.After = slices.Clone(c.After)
+		v.Outputs = make([]Output, 0, len(c.Outputs))
+		for _, o := range c.Outputs {
+			e := o.Expand(params)
+			if strings.Contains(string(e), "${") {
+				return nil, errors.New(
+					"output '%v' on matrix target '%v' references an unknown parameter", o, name)
+			}
+			v.Outputs = append(v.Outputs, e)
+		}
+		v.Locks = slices.Clone(c.Locks)
+		v.Tags = slices.Clone(c.Tags)
+
+		variants[DefineMatrixName(name, params)] = &v
+	}
+
+	return variants, nil
+}
@@ -0,0 +1,102 @@
+//go:build test && (test_small || test_all)
+
+package target
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixExpand(t *testing.T) {
+	t.Parallel()
+
+	m := Matrix{"go": {"1.24", "1.25"}, "buildType": {"debug", "release"}}
+	require.NoError(t, m.Validate())
+
+	var names []string
+	for _, p := range m.Expand() {
+		names = append(names, DefineMatrixName("build", p))
+	}
+	assert.Equal(t, []string{
+		"build[buildType=debug,go=1.24]",
+		"build[buildType=debug,go=1.25]",
+		"build[buildType=release,go=1.24]",
+		"build[buildType=release,go=1.25]",
+	}, names)
+
+	assert.Error(t, Matrix{"a b": {"1"}}.Validate())
+	assert.Error(t, Matrix{"a": {}}.Validate())
+	assert.Error(t, Matrix{"a": {"1", "1"}}.Validate())
+	assert.Error(t, Matrix{"a": {"x=y"}}.Validate())
+}
+
+func TestMatrixSplitParameters(t *testing.T) {
+	t.Parallel()
+
+	id := ID("comp::build[go=1.25,buildType=debug]")
+	base, params := id.SplitParameters()
+	assert.Equal(t, ID("comp::build"), base)
+	assert.Equal(t, Parameters{"go": "1.25", "buildType": "debug"}, params)
+	assert.True(t, Parameters{"go": "1.25", "buildType": "debug", "os": "linux"}.Matches(params))
+	assert.False(t, Parameters{"go": "1.24", "buildType": "debug"}.Matches(params))
+
+	id = ID("comp::build")
+	base, params = id.SplitParameters()
+	assert.Equal(t, id, base)
+	assert.Nil(t, params)
+}
+
+func TestMatrixExpandConfig(t *testing.T) {
+	t.Parallel()
+
+	c := Config{
+		Dependencies: []ID{"self::lint"},
+		Matrix:       Matrix{"buildType": {"debug", "release"}},
+	}
+
+	variants, err := c.ExpandMatrix("build")
+	require.NoError(t, err)
+	require.Len(t, variants, 2)
+
+	v := variants["build[buildType=release]"]
+	require.NotNil(t, v)
+	assert.Nil(t, v.Matrix)
+	assert.Equal(t, Parameters{"buildType": "release"}, v.Parameters)
+
+	// Slices are not shared.
+	v.Dependencies[0] = "other"
+	assert.Equal(t, ID("self::lint"), variants["build[buildType=debug]"].Dependencies[0])
+
+	variants, err = (&Config{}).ExpandMatrix("build")
+	require.NoError(t, err)
+	assert.Nil(t, variants)
+}
+
+func TestMatrixExpandOutputs(t *testing.T) {
+	t.Parallel()
+
+	c := Config{
+		Matrix:  Matrix{"buildType": {"debug", "release"}, "go": {"1.25"}},
+		Outputs: []Output{"out:build/${buildType}/go-${go}/*"},
+	}
+
+	variants, err := c.ExpandMatrix("build")
+	require.NoError(t, err)
+	assert.Equal(t,
+		[]Output{"out:build/release/go-1.25/*"},
+		variants["build[buildType=release,go=1.25]"].Outputs)
+	assert.Equal(t,
+		[]Output{"out:build/debug/go-1.25/*"},
+		variants["build[buildType=debug,go=1.25]"].Outputs)
+
+	// Every parameter must appear, such that variants do not share outputs.
+	c.Outputs = []Output{"out:build/${buildType}/*"}
+	_, err = c.ExpandMatrix("build")
+	require.ErrorContains(t, err, "${go}")
+
+	c.Outputs = []Output{"out:build/${buildType}/${go}/${other}"}
+	_, err = c.ExpandMatrix("build")
+	require.ErrorContains(t, err, "unknown parameter")
+}
@@ -0,0 +1,49 @@
+package target
+
+import (
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+// OutputOutDirPrefix is the prefix on an output pattern
+// which denotes it as relative to the component's output directory.
+const OutputOutDirPrefix = "out:"
+
+// Output is a glob pattern (doublestar allowed) of a path a target
+// produces. It is relative to the component's root directory or,
+// if prefixed with [OutputOutDirPrefix], relative to the component's
+// output directory, e.g. `out:build/bin/*`.
+type Output string
+
+// IsOutDir tells if the output pattern is relative to the component's
+// output directory.
+func (o Output) IsOutDir() bool {
+	return strings.HasPrefix(string(o), OutputOutDirPrefix)
+}
+
+// Pattern returns the glob pattern without the prefix.
+func (o Output) Pattern() string {
+	return path.Clean(strings.TrimPrefix(string(o), OutputOutDirPrefix))
+}
+
+// Validate validates the output pattern.
+func (o Output) Validate() error {
+	p := strings.TrimPrefix(string(o), OutputOutDirPrefix)
+
+	switch {
+	case p == "":
+		return errors.New("output pattern '%v' is empty", o)
+	case path.IsAbs(p):
+		return errors.New("output pattern '%v' must be relative", o)
+	case path.Clean(p) == "." ||
+		path.Clean(p) == ".." || strings.HasPrefix(path.Clean(p), "../"):
+		return errors.New("output pattern '%v' must not point outside its directory", o)
+	case !doublestar.ValidatePattern(p):
+		return errors.New("output pattern '%v' is not a valid glob pattern", o)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build test && (test_small || test_all)
+
+package target
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutput(t *testing.T) {
+	o := Output("out:build/bin/*")
+	assert.True(t, o.IsOutDir())
+	assert.Equal(t, "build/bin/*", o.Pattern())
+	assert.NoError(t, o.Validate())
+
+	o = Output("./dist/**/*.tar.gz")
+	assert.False(t, o.IsOutDir())
+	assert.Equal(t, "dist/**/*.tar.gz", o.Pattern())
+	assert.NoError(t, o.Validate())
+
+	for _, o := range []Output{"", "out:", "/abs", "..", "a/../../b", "out:.", "a/[b"} {
+		assert.Error(t, o.Validate(), "output '%v' should be invalid", o)
+	}
+}
@@ -0,0 +1,130 @@
+package dag
+
+import (
+	"io"
+	"os"
+	"path"
+
+	"github.com/sdsc-ordes/quitsh/pkg/cache"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+// restoreFromCache restores the outputs of all targets which need to run
+// from the cache `c` and marks them as restored.
+// Only targets with outputs are cached. Failures are only logged
+// and the target runs normally.
+func restoreFromCache(targets TargetNodeMap, c cache.ICacheBackend) {
+	for _, n := range targets {
+		if n.Execution.UpToDate || len(n.Target.Outputs) == 0 || n.Fingerprint == "" {
+			continue
+		}
+
+		restored, err := n.restoreOutputs(c)
+		if err != nil {
+			log.WarnE(err, "Could not restore target from cache.", "target", n.Target.ID)
+
+			continue
+		}
+
+		if restored {
+			log.Info("Target restored from cache.", "target", n.Target.ID, "cache", c.String())
+			n.Execution.Restored = true
+		}
+	}
+}
+
+// uploadToCache stores the outputs of all targets which ran successfully
+// in the cache `c`.
+func uploadToCache(targets TargetNodeMap, c cache.ICacheBackend) {
+	for _, n := range targets {
+		if n.Execution.UpToDate || n.Execution.Restored || n.Execution.Cancel ||
+			len(n.Target.Outputs) == 0 || n.Fingerprint == "" ||
+			n.Status() != ExecStatusSuccess {
+			continue
+		}
+
+		err := n.uploadOutputs(c)
+		if err != nil {
+			log.WarnE(err, "Could not upload target to cache.", "target", n.Target.ID)
+
+			continue
+		}
+
+		log.Info("Target uploaded to cache.", "target", n.Target.ID, "cache", c.String())
+	}
+}
+
+// restoreOutputs restores the outputs of this target from cache `c`.
+// Existing outputs are removed before.
+func (n *TargetNode) restoreOutputs(c cache.ICacheBackend) (bool, error) {
+	f, err := os.CreateTemp("", "quitsh-cache-*.tar.gz")
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	found, err := c.Get(string(n.Fingerprint), f)
+	if err != nil || !found {
+		return false, err
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	existing, _, err := n.ResolveOutputs()
+	if err != nil {
+		return false, err
+	}
+
+	dirs := n.outputDirs()
+	for _, e := range existing {
+		if err = os.RemoveAll(path.Join(dirs[e.Dir], e.Path)); err != nil {
+			return false, errors.AddContext(err,
+				"could not remove output '%v' of target '%v'", e.Path, n.Target.ID)
+		}
+	}
+
+	err = cache.Extract(f, dirs)
+	if err != nil {
+		return false, errors.AddContext(err,
+			"could not extract outputs of target '%v'", n.Target.ID)
+	}
+
+	return true, nil
+}
+
+// uploadOutputs archives the outputs of this target and stores
+// them in cache `c`.
+func (n *TargetNode) uploadOutputs(c cache.ICacheBackend) error {
+	entries, missing, err := n.ResolveOutputs()
+	if err != nil {
+		return err
+	} else if len(missing) != 0 {
+		return errors.New("outputs '%q' of target '%v' do not exist", missing, n.Target.ID)
+	}
+
+	f, err := os.CreateTemp("", "quitsh-cache-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	err = cache.Archive(f, n.outputDirs(), entries)
+	if err != nil {
+		return errors.AddContext(err, "could not archive outputs of target '%v'", n.Target.ID)
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return c.Put(string(n.Fingerprint), f)
+}
@@ -0,0 +1,70 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/cache"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheRestore(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	c := cache.NewFilesystemBackend(t.TempDir())
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	require.NoError(t, ComputeFingerprints(nodes, nil))
+
+	n := nodes["a::build"]
+	n.Target.Outputs = []target.Output{"out:build/bin/*", "src/gen"}
+
+	_, missing, err := n.ResolveOutputs()
+	require.NoError(t, err)
+	assert.Len(t, missing, 2)
+
+	// Produce the outputs.
+	require.NoError(t, os.MkdirAll(n.Comp.OutBuildDir("bin"), fs.DefaultPermissionsDir))
+	require.NoError(t,
+		os.WriteFile(n.Comp.OutBuildDir("bin", "a"), []byte("bin"), fs.DefaultPermissionsFile))
+	require.NoError(t,
+		os.WriteFile(path.Join(root, "a/src/gen"), []byte("gen"), fs.DefaultPermissionsFile))
+
+	entries, missing, err := n.ResolveOutputs()
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Len(t, entries, 2)
+
+	require.NoError(t, n.uploadOutputs(c))
+
+	// Remove and restore them.
+	require.NoError(t, os.RemoveAll(n.Comp.OutDir()))
+	require.NoError(t,
+		os.WriteFile(path.Join(root, "a/src/gen"), []byte("stale"), fs.DefaultPermissionsFile))
+
+	restored, err := n.restoreOutputs(c)
+	require.NoError(t, err)
+	assert.True(t, restored)
+
+	content, err := os.ReadFile(n.Comp.OutBuildDir("bin", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "bin", string(content))
+
+	content, err = os.ReadFile(path.Join(root, "a/src/gen"))
+	require.NoError(t, err)
+	assert.Equal(t, "gen", string(content))
+
+	// Other fingerprint is not in the cache.
+	n.Fingerprint = "other"
+	restored, err = n.restoreOutputs(c)
+	require.NoError(t, err)
+	assert.False(t, restored)
+}
@@ -1,6 +1,8 @@
 package dag
 
 import (
+	"context"
+
 	"github.com/sdsc-ordes/quitsh/pkg/component"
 	"github.com/sdsc-ordes/quitsh/pkg/component/step"
 	"github.com/sdsc-ordes/quitsh/pkg/component/target"
@@ -8,40 +10,56 @@ import (
 	"github.com/sdsc-ordes/quitsh/pkg/log"
 )
 
-// context implements the `runner.IContext` interface.
-type context struct {
+// runnerContext implements the `runner.IContext` interface.
+type runnerContext struct {
+	ctx       context.Context
 	gitx      git.Context
 	comp      *component.Component
 	targetID  target.ID
 	toolchain string
 	stepIdx   step.Index
 	log       log.ILog
+
+	depOutputs map[target.ID][]string
+	params     target.Parameters
 }
 
-func (c *context) Root() string {
+func (c *runnerContext) Root() string {
 	return c.gitx.Cwd()
 }
 
-func (c *context) Log() log.ILog {
+func (c *runnerContext) Log() log.ILog {
 	return c.log
 }
 
-func (c *context) Component() *component.Component {
+func (c *runnerContext) Component() *component.Component {
 	return c.comp
 }
 
-func (c *context) Target() target.ID {
+func (c *runnerContext) Target() target.ID {
 	return c.targetID
 }
 
-func (c *context) Step() step.Index {
+func (c *runnerContext) Step() step.Index {
 	return c.stepIdx
 }
 
-func (c *context) Toolchain() string {
+func (c *runnerContext) Toolchain() string {
 	return c.toolchain
 }
 
-func (c *context) Git() git.Context {
+func (c *runnerContext) Git() git.Context {
 	return c.gitx
 }
+
+func (c *runnerContext) DependencyOutputs() map[target.ID][]string {
+	return c.depOutputs
+}
+
+func (c *runnerContext) Parameters() target.Parameters {
+	return c.params
+}
+
+func (c *runnerContext) Ctx() context.Context {
+	return c.ctx
+}
@@ -7,6 +7,7 @@ import (
 	"path"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/sdsc-ordes/quitsh/pkg/common/recache"
 	"github.com/sdsc-ordes/quitsh/pkg/common/set"
@@ -59,6 +60,9 @@ type (
 		nodeCount int
 
 		inputPathChanges []string
+
+		shard          *Shard
+		shardDurations map[target.ID]time.Duration
 	}
 )
 
@@ -70,6 +74,7 @@ type (
 //   - The `inputPathChanges` denote the path changes which will propagate the
 //     changed flags on the target nodes. If its `nil` all targets
 //     are considered changed by default.
+//   - The shard restricts the selection to one part of it (see [WithShard]).
 func DefineExecutionOrder(
 	components []*component.Component,
 	rootDir string,
@@ -133,6 +138,13 @@ func defineExecutionOrder(
 		return nil, nil, err
 	}
 
+	if o.shard != nil {
+		o.targetSelection = selectShard(allNodes, o.targetSelection, *o.shard, o.shardDurations)
+		if o.targetSelection.Len() == 0 {
+			return nil, nil, nil
+		}
+	}
+
 	g, err := newGraph(allNodes, o.targetSelection)
 	if err != nil {
 		return nil, nil, err
@@ -167,6 +179,7 @@ func constructNodes(
 	allNodes := make(TargetNodeMap, len(components)*4) //nolint:mnd // intentional.
 	allInputs := make(map[input.ID]*input.Config, len(components))
 	allComps := make(map[string]*component.Component, len(components))
+	registry := &inputRegistry{inputs: allInputs, comps: allComps}
 
 	// Add all components we found as nodes to the graph.
 	for _, c := range components {
@@ -194,7 +207,7 @@ func constructNodes(
 				)
 			}
 
-			tNode := &TargetNode{Target: t, Comp: c, Config: config}
+			tNode := &TargetNode{Target: t, Comp: c, Config: config, registry: registry}
 
 			// Resolve target ids.
 			resolveTargetIDs(tNode)
@@ -203,8 +216,13 @@ func constructNodes(
 		}
 	}
 
+	err := resolveMatrixIDs(allNodes, targetSelection)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	log.Debug("Connect all target nodes.")
-	allNodes, err := connectNodes(allNodes, targetSelection)
+	allNodes, err = connectNodes(allNodes, targetSelection)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -348,6 +366,10 @@ type traverseDirection int
 const backwardDir traverseDirection = 0
 const forwardDir traverseDirection = 1
 
+// backwardAfterDir traverses backward over the dependencies
+// and the weak ordering (`.After`).
+const backwardAfterDir traverseDirection = 2
+
 // filterNodesBFS filters the graph in breadth-first manner with a `filter` function.
 func filterNodesBFS(
 	startNodes []*TargetNode,
@@ -387,6 +409,9 @@ func visitNodesBFS(
 			bfsStack.Push(n.Backward...)
 		case forwardDir:
 			bfsStack.Push(n.Forward...)
+		case backwardAfterDir:
+			bfsStack.Push(n.Backward...)
+			bfsStack.Push(n.After...)
 		default:
 			panic("wrong direction")
 		}
@@ -444,18 +469,113 @@ func resolveInputIDs(
 	return nil
 }
 
-// resolveTargetIDs resolves all `self::XXX` target ids in `.Dependencies`.
+// resolveTargetIDs resolves all `self::XXX` target ids in `.Dependencies` and `.After`.
 func resolveTargetIDs(node *TargetNode) {
 	log.Debug("Resolve target ids.")
-	for idx, targetID := range node.Target.Dependencies {
-		// Mangle `self::` into own components input id.
-		trimmedID := strings.TrimPrefix(string(targetID), "self::")
+	for _, ids := range [][]target.ID{node.Target.Dependencies, node.Target.After} {
+		for idx, targetID := range ids {
+			// Mangle `self::` into own components input id.
+			trimmedID := strings.TrimPrefix(string(targetID), "self::")
+
+			if trimmedID != string(targetID) {
+				ids[idx] = target.DefineID(node.Config.Name, trimmedID)
+			}
+		}
+	}
+}
+
+// resolveMatrixIDs resolves all target ids in `.Dependencies`, `.After` and
+// the selection `sel` which refer to matrix targets by their base id
+// (e.g. `comp::build`, all variants) or by a subset of the parameters
+// (e.g. `comp::build[buildType=release]`, all matching variants).
+func resolveMatrixIDs(nodes TargetNodeMap, sel *TargetSelection) (err error) {
+	variants := make(map[target.ID][]target.ID)
+	for id, n := range nodes {
+		if len(n.Target.Parameters) != 0 {
+			base, _ := id.SplitParameters()
+			variants[base] = append(variants[base], id)
+		}
+	}
+
+	if len(variants) == 0 {
+		return nil
+	}
+
+	for _, n := range nodes {
+		n.Target.Dependencies, err = expandMatrixIDs(n.Target.Dependencies, nodes, variants)
+		if err != nil {
+			return errors.AddContext(err, "in dependencies of target '%v'", n.Target.ID)
+		}
+
+		n.Target.After, err = expandMatrixIDs(n.Target.After, nodes, variants)
+		if err != nil {
+			return errors.AddContext(err, "in after of target '%v'", n.Target.ID)
+		}
+	}
+
+	if sel != nil {
+		ids, e := expandMatrixIDs(slices.Collect(sel.Keys()), nodes, variants)
+		if e != nil {
+			return errors.AddContext(e, "in target selection")
+		}
+
+		*sel = set.NewUnordered(ids...)
+	}
+
+	return nil
+}
+
+// expandMatrixIDs replaces all ids in `ids` which are not in `nodes`
+// but refer to matrix targets in `variants` by the matching variants.
+func expandMatrixIDs(
+	ids []target.ID,
+	nodes TargetNodeMap,
+	variants map[target.ID][]target.ID,
+) ([]target.ID, error) {
+	unresolved := slices.ContainsFunc(ids, func(id target.ID) bool {
+		_, exists := nodes[id]
 
-		if trimmedID != string(targetID) {
-			targetID = target.DefineID(node.Config.Name, trimmedID)
-			node.Target.Dependencies[idx] = targetID
+		return !exists
+	})
+	if !unresolved {
+		return ids, nil
+	}
+
+	result := make([]target.ID, 0, len(ids))
+
+	for _, id := range ids {
+		if _, exists := nodes[id]; exists {
+			result = append(result, id)
+
+			continue
 		}
+
+		base, params := id.SplitParameters()
+		vs, exists := variants[base]
+		if !exists {
+			// Not a matrix target, reported when connecting.
+			result = append(result, id)
+
+			continue
+		}
+
+		var matched []target.ID
+		for _, v := range vs {
+			if nodes[v].Target.Parameters.Matches(params) {
+				matched = append(matched, v)
+			}
+		}
+
+		if len(matched) == 0 {
+			return nil, errors.New(
+				"target id '%v' matches no variant of matrix target '%v'", id, base)
+		}
+
+		slices.Sort(matched)
+		result = append(result, matched...)
 	}
+
+	return result, nil
 }
 
 func connectNodes(nodes TargetNodeMap, sel *TargetSelection) (TargetNodeMap, error) {
@@ -522,69 +642,97 @@ func connectNodes(nodes TargetNodeMap, sel *TargetSelection) (TargetNodeMap, err
 		dfsStack.Push(n.Target.Dependencies...)
 	}
 
+	err := connectAfterNodes(nodes, visited)
+	if err != nil {
+		return nil, err
+	}
+
 	return visited, nil
 }
 
+// connectAfterNodes connects the weak ordering `.After` between all
+// `reached` nodes. Targets which are not reached are not connected.
+func connectAfterNodes(nodes TargetNodeMap, reached TargetNodeMap) error {
+	for _, n := range reached {
+		visitedAfter := set.NewUnordered[target.ID]()
+
+		for _, id := range n.Target.After {
+			if _, exists := nodes[id]; !exists {
+				return errors.New(
+					"after target id '%s' defined on target '%s' does not exist\n"+
+						"  -> working directory (or '-C') might be at the wrong place (use the top-level to check)",
+					id,
+					n.Target.ID,
+				)
+			}
+
+			afterNode, ok := reached[id]
+			if !ok || visitedAfter.Exists(id) {
+				continue
+			}
+			visitedAfter.Insert(id)
+
+			n.After = append(n.After, afterNode)
+		}
+	}
+
+	return nil
+}
+
 // CheckNoCycles checks that the graph has no cycles by traversing it in
-// depth-first manner from all roots.
-func (graph *graph) CheckNoCycles() (err error) {
+// depth-first manner from all nodes over the dependencies and the
+// weak ordering (`.After`).
+func (graph *graph) CheckNoCycles() error {
 	log.Debug("Check for cycles.")
 
-	startNodes := slices.Collect(maps.Values(graph.nodes))
-	visited := set.NewUnordered[target.ID]()
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
 
-	// Check for every start node if we run into a cycle.
-	for _, root := range startNodes {
-		if visited.Exists(root.Target.ID) {
-			// DFS cycle search was already on this path, so skip it.
-			continue
-		}
+	state := make(map[target.ID]int, len(graph.nodes))
+	pathStack := stack.NewStack[target.ID]()
 
-		idsOnPath := make(map[target.ID]struct{})
-		dfsStack := stack.NewStack[*TargetNode]()
-		pathStack := stack.NewStack[target.ID]()
+	var visit func(n *TargetNode) error
+	visit = func(n *TargetNode) error {
+		id := n.Target.ID
 
-		stepDown := func(id target.ID) {
+		switch state[id] {
+		case done:
+			return nil
+		case onPath:
 			pathStack.Push(id)
-			idsOnPath[id] = struct{}{}
-		}
 
-		stepUpwards := func(id target.ID) {
-			pathStack.Pop()
-			delete(idsOnPath, id)
+			return errors.New(
+				"direct acyclic graph contains a cycle in the following target chain:\n%v",
+				formatPath(&pathStack),
+			)
 		}
 
-		log.Tracef("Start DFS cycle check: %v", root.Target.ID)
-		dfsStack.Push(root)
+		state[id] = onPath
+		pathStack.Push(id)
+		log.Tracef("Current DFS path:\n%v", formatPath(&pathStack))
 
-		for dfsStack.Len() != 0 {
-			currNode := dfsStack.Pop()
-			currID := currNode.Target.ID
-
-			visited.Insert(currID)
-
-			if _, exists := idsOnPath[currID]; exists {
-				return errors.Combine(
-					err,
-					errors.New(
-						"direct acyclic graph contains a cycle in the following target chain:\n%v",
-						formatPath(&pathStack),
-					),
-				)
+		for _, next := range slices.Concat(n.Backward, n.After) {
+			if err := visit(next); err != nil {
+				return err
 			}
+		}
 
-			stepDown(currID)
-			log.Tracef("Current DFS path:\n%v", formatPath(&pathStack))
+		pathStack.Pop()
+		state[id] = done
 
-			if len(currNode.Backward) == 0 {
-				stepUpwards(currID)
-			} else {
-				dfsStack.Push(currNode.Backward...)
-			}
+		return nil
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(graph.nodes)) {
+		if err := visit(graph.nodes[id]); err != nil {
+			return err
 		}
 	}
 
-	return err
+	return nil
 }
 
 func (graph *graph) SolveExecutionOrder() error {
@@ -593,14 +741,15 @@ func (graph *graph) SolveExecutionOrder() error {
 	visitNodesBFS(
 		*graph.execLeafNodesSel,
 		func(n *TargetNode) bool {
-			// The nodes we depend on must have a priority strictly higher than the current node.
-			for _, d := range n.Backward {
+			// The nodes we depend on or run after must have a priority
+			// strictly higher than the current node.
+			for _, d := range slices.Concat(n.Backward, n.After) {
 				d.Priority = max(n.Priority+1, d.Priority)
 			}
 
 			return true
 		},
-		backwardDir)
+		backwardAfterDir)
 
 	return nil
 }
@@ -655,7 +804,7 @@ func (graph *graph) SolveInputChanges(
 					"when we compute own changes, "+
 						"we need a paths set and resolved inputs")
 
-				changed, changes, err := determineChangedPaths(
+				ch, err := determineChangedPaths(
 					n,
 					inputs,
 					comps,
@@ -667,8 +816,9 @@ func (graph *graph) SolveInputChanges(
 					return err
 				}
 
-				currIn.Changed = changed
-				currIn.Paths = changes
+				currIn.Changed = ch.Changed
+				currIn.Paths = ch.Changes
+				currIn.Reason = ch.Reason
 
 			default:
 				log.Tracef("Current target '%s' already changed.", n.Target.ID)
@@ -692,6 +842,9 @@ func (graph *graph) SolveInputChanges(
 			// Propagate to children, by merging the input changes.
 			for _, c := range n.Forward {
 				c.Inputs.Propagate(&n.Inputs)
+				if currIn.IsChanged() && c.Inputs.ChangedBy == nil {
+					c.Inputs.ChangedBy = n
+				}
 			}
 
 			// Go to next nodes.
@@ -699,6 +852,16 @@ func (graph *graph) SolveInputChanges(
 		}
 	}
 
+	if changedInSelection.Len() == 0 {
+		log.Debug("No target changed -> selection subgraph is empty.")
+		empty := set.NewUnordered[target.ID]()
+		graph.nodesSel = &empty
+		graph.execLeafNodesSel = &[]*TargetNode{}
+		graph.execRootNodesSel = &[]*TargetNode{}
+
+		return nil
+	}
+
 	return graph.recomputeSubgraph(&changedInSelection)
 }
 
@@ -778,7 +941,8 @@ func determineChangedPathsDefault(
 	return
 }
 
-// determineChangedPaths determines if this target has changes on its own.
+// determineChangedPaths determines if this target has changes on its own
+// and the reason of the first matched path.
 //
 //nolint:gocognit
 func determineChangedPaths(
@@ -788,7 +952,7 @@ func determineChangedPaths(
 	inputChanges map[input.ID]InputChanges,
 	regexCache *recache.Cache,
 	paths []string,
-) (bool, []string, error) {
+) (InputChanges, error) {
 	if node.Target.Inputs == nil {
 		// If no input ids, just add its own component, which is the default.
 		inputID := input.DefineIDComp(node.Comp.Name())
@@ -804,7 +968,7 @@ func determineChangedPaths(
 			// Input changeset already determined.
 			log.Tracef("Input changeset already changed.")
 
-			return inputCh.Changed, inputCh.Changes, nil
+			return inputCh, nil
 		}
 
 		// Not determined yet, lets check.
@@ -814,10 +978,15 @@ func determineChangedPaths(
 			debug.Assert(comp != nil, "component referred is not existing")
 			changed, changes := determineChangedPathsDefault(comp.Root(), paths)
 			if changed {
-				inputChanges[inputID] = InputChanges{Changed: changed, Changes: changes}
+				inputCh = InputChanges{
+					Changed: changed,
+					Changes: changes,
+					Reason:  &ChangeReason{InputID: inputID, Path: changes[0]},
+				}
+				inputChanges[inputID] = inputCh
 
 				// Lazy, do not check other input sets.
-				return changed, changes, nil
+				return inputCh, nil
 			}
 
 			continue
@@ -827,7 +996,7 @@ func determineChangedPaths(
 		log.Tracef("Check for changes for input id '%v'", inputID)
 		input, exists := inputs[inputID]
 		if !exists {
-			return false, nil,
+			return InputChanges{},
 				errors.New("input id '%s' does not exist (programming error?)", inputID)
 		}
 
@@ -847,7 +1016,7 @@ func determineChangedPaths(
 
 		includeRegexes, e := regexCache.Get(input.Includes()...)
 		if e != nil {
-			return false, nil, errors.AddContext(
+			return InputChanges{}, errors.AddContext(
 				e,
 				"failed to get include regexes in target id '%s' for input id '%s'",
 				node.Target.ID,
@@ -857,7 +1026,7 @@ func determineChangedPaths(
 
 		excludeRegexes, e := regexCache.Get(input.Excludes()...)
 		if e != nil {
-			return false, nil, errors.AddContext(
+			return InputChanges{}, errors.AddContext(
 				e,
 				"failed to get exclude regexes in target id '%s' for input id '%s'",
 				node.Target.ID,
@@ -866,18 +1035,26 @@ func determineChangedPaths(
 		}
 
 		for _, p := range relativePaths {
-			changed := includeRegexes.Match(p) && !excludeRegexes.Match(p)
-			if changed {
+			include := includeRegexes.Find(p)
+			if include != nil && !excludeRegexes.Match(p) {
 				log.Tracef("Matched path '%v'.", p)
-				inputCh = InputChanges{changed, []string{p}}
+				inputCh = InputChanges{
+					Changed: true,
+					Changes: []string{p},
+					Reason: &ChangeReason{
+						InputID: inputID,
+						Pattern: include.String(),
+						Path:    p,
+					},
+				}
 				inputChanges[inputID] = inputCh // set the changes back!
 
-				return inputCh.Changed, inputCh.Changes, nil
+				return inputCh, nil
 			}
 		}
 	}
 
-	return false, nil, nil
+	return InputChanges{}, nil
 }
 
 // formatPath formats the current stack with some
@@ -1007,6 +1184,46 @@ func WithTargetSelectionAdd(ids ...target.ID) ExecOption {
 	}
 }
 
+// WithTargetSelectionRestrict restricts the target selection to the ids `ids`
+// (intersection). If no selection is set before, the selection is `ids`.
+func WithTargetSelectionRestrict(ids ...target.ID) ExecOption {
+	return func(o *opts) error {
+		restrict := set.NewUnordered(ids...)
+
+		if o.targetSelection == nil {
+			o.targetSelection = &restrict
+
+			return nil
+		}
+
+		for _, id := range slices.Collect(o.targetSelection.Keys()) {
+			if !restrict.Exists(id) {
+				o.targetSelection.Remove(id)
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithShard restricts the selection to the shard `shard`: The selected targets
+// (or all leaf targets without selection) are split into `shard.Count` balanced
+// shards, each of which executes its targets together with their dependencies.
+// The recorded `durations` (can be `nil`, see [LoadShardDurations])
+// balance the shards by execution time instead of target count.
+func WithShard(shard Shard, durations map[target.ID]time.Duration) ExecOption {
+	return func(o *opts) error {
+		if err := shard.Validate(); err != nil {
+			return err
+		}
+
+		o.shard = &shard
+		o.shardDurations = durations
+
+		return nil
+	}
+}
+
 // WithInputChanges set the input path changes to be considered.
 func WithInputChanges(inputPathChanges []string) ExecOption {
 	return func(o *opts) error {
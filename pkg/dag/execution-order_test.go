@@ -3,6 +3,7 @@
 package dag
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/sdsc-ordes/quitsh/pkg/common/set"
@@ -328,6 +329,177 @@ func TestGraphExecOrderSimpleNoConnection(t *testing.T) {
 	assert.Len(t, prios, 1)
 }
 
+func TestGraphExecOrderAfter(t *testing.T) {
+	t.Parallel()
+
+	// Both selected: `1::lint` runs strictly before `1::test`.
+	comps := generateAfterComps(t)
+	targets, prios, e := DefineExecutionOrder(comps, rootDir)
+	require.NoError(t, e)
+	require.Len(t, targets, 3)
+	assert.Greater(t, targets["1::lint"].Priority, targets["1::test"].Priority)
+	assert.Len(t, prios, 2)
+
+	// Only `1::test` selected: `1::lint` is not pulled in.
+	comps = generateAfterComps(t)
+	sel := set.NewUnordered[target.ID]("1::test")
+	targets, _, e = DefineExecutionOrder(comps, rootDir, WithTargetSelection(&sel))
+	require.NoError(t, e)
+	assert.Len(t, targets, 2)
+	assert.NotContains(t, targets, target.ID("1::lint"))
+	assert.Empty(t, targets["1::test"].After)
+}
+
+func TestGraphExecOrderAfterCycle(t *testing.T) {
+	t.Parallel()
+
+	comps := generateAfterComps(t)
+	lint := comps[0].Config().Targets["lint"]
+	lint.After = append(lint.After, "1::test")
+
+	_, _, e := DefineExecutionOrder(comps, rootDir)
+	require.ErrorContains(t, e, "contains a cycle")
+}
+
+func TestGraphExecOrderChangesTransitive(t *testing.T) {
+	t.Parallel()
+
+	// Only 1 changed.
+	comps := generateChainComps(t)
+
+	targets, _, e := DefineExecutionOrder(
+		comps,
+		rootDir,
+		WithInputChanges([]string{"/repo/components/1/file"}),
+	)
+	require.NoError(t, e)
+	require.Len(t, targets, 3)
+	assert.NotContains(t, targets, target.ID("4::build"))
+
+	assert.True(t, targets["1::build"].Inputs.Changed)
+	assert.False(t, targets["3::build"].Inputs.Changed)
+	assert.True(t, targets["3::build"].Inputs.ChangedByDependency)
+
+	// Nothing changed: nothing is selected.
+	targets, _, e = DefineExecutionOrder(comps, rootDir, WithInputChanges([]string{}))
+	require.NoError(t, e)
+	assert.Empty(t, targets)
+}
+
+func TestGraphExecOrderMatrix(t *testing.T) {
+	t.Parallel()
+
+	comps := generateMatrixComps(t)
+	targets, _, e := DefineExecutionOrder(comps, rootDir)
+	require.NoError(t, e)
+	require.Len(t, targets, 7)
+
+	// Depends on all variants.
+	var deps []target.ID
+	for _, n := range targets["1::test-all"].Backward {
+		deps = append(deps, n.Target.ID)
+	}
+	assert.ElementsMatch(t, []target.ID{
+		"1::build[buildType=debug,go=1.24]",
+		"1::build[buildType=debug,go=1.25]",
+		"1::build[buildType=release,go=1.24]",
+		"1::build[buildType=release,go=1.25]",
+	}, deps)
+
+	// Depends on the matching variants.
+	deps = nil
+	for _, n := range targets["1::test-release"].Backward {
+		deps = append(deps, n.Target.ID)
+	}
+	assert.ElementsMatch(t, []target.ID{
+		"1::build[buildType=release,go=1.24]",
+		"1::build[buildType=release,go=1.25]",
+	}, deps)
+
+	require.Len(t, targets["1::test-one"].Backward, 1)
+	assert.Equal(t,
+		target.Parameters{"buildType": "debug", "go": "1.25"},
+		targets["1::test-one"].Backward[0].Target.Parameters)
+
+	// Selection by a subset of the parameters selects the matching variants.
+	comps = generateMatrixComps(t)
+	sel := set.NewUnordered[target.ID]("1::build[go=1.24]")
+	targets, _, e = DefineExecutionOrder(comps, rootDir, WithTargetSelection(&sel))
+	require.NoError(t, e)
+	assert.Len(t, targets, 2)
+
+	// No matching variant.
+	comps = generateMatrixComps(t)
+	test := comps[0].Config().Targets["test-one"]
+	test.Dependencies = []target.ID{"self::build[go=1.0]"}
+	_, _, e = DefineExecutionOrder(comps, rootDir)
+	require.ErrorContains(t, e, "matches no variant")
+}
+
+func generateMatrixComps(t *testing.T) []*component.Component {
+	conf := &component.Config{
+		Name:     "1",
+		Language: "go",
+		Targets: map[string]*target.Config{
+			"build": {
+				Matrix: target.Matrix{
+					"buildType": {"debug", "release"},
+					"go":        {"1.24", "1.25"},
+				},
+			},
+			"test-all":     {Dependencies: []target.ID{"self::build"}},
+			"test-release": {Dependencies: []target.ID{"1::build[buildType=release]"}},
+			"test-one":     {Dependencies: []target.ID{"self::build[go=1.25,buildType=debug]"}},
+		},
+	}
+	require.NoError(t, conf.Init())
+	comp := component.NewComponent(conf, "/repo/components/1", "", "")
+
+	return []*component.Component{&comp}
+}
+
+func generateChainComps(t *testing.T) []*component.Component {
+	// Create a chain 1::build <- 2::build <- 3::build
+	// and an unrelated 4::build.
+	var comps []*component.Component
+	for i, deps := range [][]target.ID{nil, {"1::build"}, {"2::build"}, nil} {
+		conf := &component.Config{
+			Name:     fmt.Sprintf("%v", i+1),
+			Language: "go",
+			Targets: map[string]*target.Config{
+				"build": {Stage: "build", Dependencies: deps},
+			},
+		}
+		require.NoError(t, conf.Init())
+		comp := component.NewComponent(conf, fmt.Sprintf("/repo/components/%v", i+1), "", "")
+		comps = append(comps, &comp)
+	}
+
+	return comps
+}
+
+func generateAfterComps(t *testing.T) []*component.Component {
+	// Create a simple graph with a weak ordering:
+	// 1::build <- 1::test
+	// 1::lint <~ 1::test (after)
+	conf := &component.Config{
+		Name:     "1",
+		Language: "go",
+		Targets: map[string]*target.Config{
+			"build": {},
+			"lint":  {},
+			"test": {
+				Dependencies: []target.ID{"self::build"},
+				After:        []target.ID{"self::lint"},
+			},
+		},
+	}
+	require.NoError(t, conf.Init())
+	comp := component.NewComponent(conf, "/repo/components/1", "", "")
+
+	return []*component.Component{&comp}
+}
+
 func generate3Comps(t *testing.T) ([]*component.Component, []string) {
 	// Create a simple graph:
 	// 1 <- 2 <- 3
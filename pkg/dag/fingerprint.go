@@ -0,0 +1,289 @@
+package dag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	stderr "errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sdsc-ordes/quitsh/pkg/common/recache"
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/input"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+	"github.com/sdsc-ordes/quitsh/pkg/tags"
+)
+
+type (
+	// Fingerprint is the content hash of a target.
+	// It covers the content of all files matched by the target's inputs,
+	// its step configs, its toolchains and the fingerprints of all
+	// its dependencies.
+	Fingerprint string
+
+	// inputRegistry holds all input sets and components a target node's inputs
+	// are resolved against.
+	inputRegistry struct {
+		inputs map[input.ID]*input.Config
+		comps  map[string]*component.Component
+	}
+
+	fingerprinter struct {
+		tags       []tags.Tag
+		regexCache recache.Cache
+
+		// Digests of input sets already computed.
+		inputDigests map[input.ID]string
+	}
+)
+
+// fingerprintFile is the file where the fingerprint of the last successful run
+// of the target is stored.
+func (n *TargetNode) fingerprintFile() string {
+	return n.Comp.OutFingerprintDir(n.Target.ID.Name())
+}
+
+// LoadFingerprint loads the fingerprint of the last successful run.
+// Returns an empty fingerprint if there is none.
+func (n *TargetNode) LoadFingerprint() (Fingerprint, error) {
+	f, err := os.ReadFile(n.fingerprintFile())
+	if stderr.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.AddContext(err,
+			"could not read fingerprint of target '%v'", n.Target.ID)
+	}
+
+	return Fingerprint(strings.TrimSpace(string(f))), nil
+}
+
+// StoreFingerprint stores the current fingerprint as the one
+// of the last successful run.
+func (n *TargetNode) StoreFingerprint() error {
+	if n.Fingerprint == "" {
+		return nil
+	}
+
+	file := n.fingerprintFile()
+	err := os.MkdirAll(n.Comp.OutFingerprintDir(), fs.DefaultPermissionsDir)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(file, []byte(n.Fingerprint+"\n"), fs.DefaultPermissionsFile)
+	if err != nil {
+		return errors.AddContext(err,
+			"could not write fingerprint of target '%v'", n.Target.ID)
+	}
+
+	return nil
+}
+
+// ComputeFingerprints computes the fingerprints of all target nodes `nodes`
+// and sets [TargetNode.Fingerprint].
+// The executable tags `tags` are part of the fingerprint since they
+// include/exclude steps.
+func ComputeFingerprints(nodes TargetNodeMap, tags []tags.Tag) error {
+	log.Debug("Compute target fingerprints.")
+
+	f := fingerprinter{
+		tags:         tags,
+		regexCache:   recache.NewCache(true),
+		inputDigests: make(map[input.ID]string),
+	}
+
+	// Traverse in sorted order to get stable logs.
+	ids := slices.Sorted(maps.Keys(nodes))
+	for _, id := range ids {
+		if _, err := f.compute(nodes[id]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compute computes the fingerprint of node `n` (depth-first over the dependencies).
+func (f *fingerprinter) compute(n *TargetNode) (Fingerprint, error) {
+	if n.Fingerprint != "" {
+		return n.Fingerprint, nil
+	}
+
+	if n.registry == nil {
+		return "", errors.New(
+			"target '%v' has no input registry (programming error?)", n.Target.ID)
+	}
+
+	err := resolveInputIDs(n, n.registry.inputs, n.registry.comps)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "target: %v\n", n.Target.ID)
+
+	inputIDs := n.Target.Inputs
+	if len(inputIDs) == 0 {
+		// If no input ids, the whole component is the input (default).
+		inputIDs = []input.ID{input.DefineIDComp(n.Comp.Name())}
+	}
+
+	for _, inputID := range inputIDs {
+		d, e := f.inputDigest(n, inputID)
+		if e != nil {
+			return "", e
+		}
+		fmt.Fprintf(h, "input: %v %v\n", inputID, d)
+	}
+
+	for i := range n.Target.Steps {
+		s := &n.Target.Steps[i]
+		fmt.Fprintf(h,
+			"step: %v runner: '%v' runnerID: '%v' toolchain: '%v' include: '%v' %v\n",
+			s.Index, s.Runner, s.RunnerID, s.Toolchain,
+			s.Include.TagExpr.String(), s.Include.TagExpr.Matches(f.tags))
+		fmt.Fprintf(h, "config: %#v\n", s.ConfigRaw.Value())
+	}
+
+	deps := slices.Clone(n.Backward)
+	slices.SortFunc(deps, func(a, b *TargetNode) int {
+		return strings.Compare(string(a.Target.ID), string(b.Target.ID))
+	})
+
+	for _, d := range deps {
+		fp, e := f.compute(d)
+		if e != nil {
+			return "", e
+		}
+		fmt.Fprintf(h, "depends: %v %v\n", d.Target.ID, fp)
+	}
+
+	n.Fingerprint = Fingerprint(hex.EncodeToString(h.Sum(nil)))
+	log.Debug("Target fingerprint.", "id", n.Target.ID, "fingerprint", n.Fingerprint)
+
+	return n.Fingerprint, nil
+}
+
+// inputDigest computes the digest over all files matched by input set `inputID`.
+func (f *fingerprinter) inputDigest(n *TargetNode, inputID input.ID) (string, error) {
+	if d, exists := f.inputDigests[inputID]; exists {
+		return d, nil
+	}
+
+	var baseDir string
+	var match func(rel string) bool
+	var opts []fs.FindOptions
+
+	if inputID.IsComponent() {
+		comp, exists := n.registry.comps[string(inputID)]
+		if !exists || comp == nil {
+			return "", errors.New("component '%v' referred to by input id "+
+				"in target '%v' does not exist", inputID, n.Target.ID)
+		}
+
+		baseDir = comp.Root()
+		match = func(string) bool { return true }
+
+		// The outputs of the component's targets are no inputs, otherwise
+		// a target would change by running it.
+		opts = append(opts, fs.WithPathFilter(
+			func(p string, _ os.DirEntry) bool { return !isComponentOutput(comp, p) }, true))
+	} else {
+		in, exists := n.registry.inputs[inputID]
+		if !exists {
+			return "", errors.New("input id '%s' does not exist (programming error?)", inputID)
+		}
+
+		includes, e := f.regexCache.Get(in.Includes()...)
+		if e != nil {
+			return "", errors.AddContext(e,
+				"failed to get include regexes for input id '%s'", inputID)
+		}
+		excludes, e := f.regexCache.Get(in.Excludes()...)
+		if e != nil {
+			return "", errors.AddContext(e,
+				"failed to get exclude regexes for input id '%s'", inputID)
+		}
+
+		baseDir = in.BaseDir
+		// Note: Not using `recache.List.Match` as it logs every match.
+		match = func(rel string) bool {
+			return slices.ContainsFunc(includes, func(r *regexp.Regexp) bool { return r.MatchString(rel) }) &&
+				!slices.ContainsFunc(excludes, func(r *regexp.Regexp) bool { return r.MatchString(rel) })
+		}
+	}
+
+	files, _, err := fs.FindFiles(baseDir, opts...)
+	if err != nil {
+		return "", errors.AddContext(err,
+			"could not find files for input id '%v' in '%v'", inputID, baseDir)
+	}
+
+	type entry struct{ rel, digest string }
+	var entries []entry
+
+	for _, file := range files {
+		rel, ok := input.BaseDir(baseDir).TrimOffFrom(file)
+		if !ok || !match(rel) {
+			continue
+		}
+
+		d, e := fileDigest(file)
+		if e != nil {
+			return "", e
+		}
+		entries = append(entries, entry{rel, d})
+	}
+
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.rel, b.rel) })
+
+	h := sha256.New()
+	for i := range entries {
+		fmt.Fprintf(h, "%s\x00%s\n", entries[i].rel, entries[i].digest)
+	}
+
+	d := hex.EncodeToString(h.Sum(nil))
+	f.inputDigests[inputID] = d
+
+	log.Trace("Input digest.", "id", inputID, "files", len(entries), "digest", d)
+
+	return d, nil
+}
+
+// fileDigest computes the digest of file `file`.
+// Symlinks are hashed by their target path.
+func fileDigest(file string) (string, error) {
+	info, err := os.Lstat(file)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, e := os.Readlink(file)
+		if e != nil {
+			return "", e
+		}
+		fmt.Fprintf(h, "symlink: %s", target)
+	} else {
+		r, e := os.Open(file)
+		if e != nil {
+			return "", e
+		}
+		defer r.Close()
+
+		if _, e = io.Copy(h, r); e != nil {
+			return "", errors.AddContext(e, "could not hash file '%v'", file)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
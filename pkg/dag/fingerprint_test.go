@@ -0,0 +1,167 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/input"
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateFingerprintComps(t *testing.T, root string) []*component.Component {
+	// Create a simple graph:
+	// a::build <- b::build
+	write := func(p, content string) {
+		p = path.Join(root, p)
+		require.NoError(t, os.MkdirAll(path.Dir(p), fs.DefaultPermissionsDir))
+		require.NoError(t, os.WriteFile(p, []byte(content), fs.DefaultPermissionsFile))
+	}
+
+	write("a/src/main.go", "package main")
+	write("a/README.md", "docs")
+	write("b/src/lib.go", "package lib")
+
+	confA := &component.Config{
+		Name:     "a",
+		Language: "go",
+		Inputs: map[string]*input.Config{
+			"srcs": {Patterns: []string{"^src/.*\\.go$"}},
+		},
+		Targets: map[string]*target.Config{
+			"build": {Stage: "build", Inputs: []input.ID{"self::srcs"}},
+		},
+	}
+	require.NoError(t, confA.Init())
+	compA := component.NewComponent(confA, path.Join(root, "a"), "", "")
+
+	confB := &component.Config{
+		Name:     "b",
+		Language: "go",
+		Targets: map[string]*target.Config{
+			"build": {Stage: "build", Dependencies: []target.ID{"a::build"}},
+		},
+	}
+	require.NoError(t, confB.Init())
+	compB := component.NewComponent(confB, path.Join(root, "b"), "", "")
+
+	return []*component.Component{&compA, &compB}
+}
+
+func fingerprints(t *testing.T, root string) (Fingerprint, Fingerprint) {
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	require.NoError(t, ComputeFingerprints(nodes, nil))
+
+	return nodes["a::build"].Fingerprint, nodes["b::build"].Fingerprint
+}
+
+func TestFingerprintStable(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	a1, b1 := fingerprints(t, root)
+	a2, b2 := fingerprints(t, root)
+
+	assert.NotEmpty(t, a1)
+	assert.NotEmpty(t, b1)
+	assert.NotEqual(t, a1, b1)
+	assert.Equal(t, a1, a2)
+	assert.Equal(t, b1, b2)
+}
+
+func TestFingerprintChanges(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	a1, b1 := fingerprints(t, root)
+
+	// Not matched by the input set of `a::build`.
+	require.NoError(t,
+		os.WriteFile(path.Join(root, "a/README.md"), []byte("changed"), fs.DefaultPermissionsFile))
+	a2, b2 := fingerprints(t, root)
+	assert.Equal(t, a1, a2)
+	assert.Equal(t, b1, b2)
+
+	// Matched by the input set of `a::build` -> propagates to `b::build`.
+	require.NoError(t,
+		os.WriteFile(path.Join(root, "a/src/new.go"), []byte("package main"), fs.DefaultPermissionsFile))
+	a3, b3 := fingerprints(t, root)
+	assert.NotEqual(t, a1, a3)
+	assert.NotEqual(t, b1, b3)
+}
+
+func TestFingerprintStore(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	require.NoError(t, ComputeFingerprints(nodes, nil))
+
+	n := nodes["a::build"]
+	last, err := n.LoadFingerprint()
+	require.NoError(t, err)
+	assert.Empty(t, last)
+
+	require.NoError(t, n.StoreFingerprint())
+	last, err = n.LoadFingerprint()
+	require.NoError(t, err)
+	assert.Equal(t, n.Fingerprint, last)
+}
+
+func TestExecuteUpToDateWithRootOutputs(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	// The target hashes the whole component which contains its output.
+	conf := &component.Config{
+		Name:     "a",
+		Language: "go",
+		Targets: map[string]*target.Config{
+			"build": {
+				Inputs:  []input.ID{"self"},
+				Outputs: []target.Output{"bin"},
+				Steps:   []step.Config{{RunnerID: "test"}},
+			},
+		},
+	}
+	require.NoError(t, conf.Init())
+	comp := component.NewComponent(conf, path.Join(root, "a"), "", "")
+	require.NoError(t, os.MkdirAll(comp.Root(), fs.DefaultPermissionsDir))
+	require.NoError(t,
+		os.WriteFile(path.Join(comp.Root(), "main.go"), []byte("package main"), fs.DefaultPermissionsFile))
+
+	runs := 0
+	f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+		runs++
+		bin := path.Join(ctx.Component().Root(), "bin")
+		if e := os.MkdirAll(bin, fs.DefaultPermissionsDir); e != nil {
+			return e
+		}
+
+		return os.WriteFile(path.Join(bin, "app"), []byte{byte(runs)}, fs.DefaultPermissionsFile)
+	})
+
+	execute := func() *TargetNode {
+		nodes, prios, err := DefineExecutionOrder([]*component.Component{&comp}, root)
+		require.NoError(t, err)
+		require.NoError(t, Execute(nodes, prios, f, nil, nil, root, false))
+
+		return nodes["a::build"]
+	}
+
+	execute()
+	n := execute()
+	assert.Equal(t, 1, runs)
+	assert.True(t, n.Execution.UpToDate)
+}
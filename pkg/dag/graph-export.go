@@ -0,0 +1,204 @@
+package dag
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+// GraphFormat is the format of an exported graph (see [ExportGraph]).
+type GraphFormat string
+
+const (
+	GraphFormatDot     GraphFormat = "dot"
+	GraphFormatMermaid GraphFormat = "mermaid"
+	GraphFormatJSON    GraphFormat = "json"
+)
+
+// GraphFormats are all supported graph formats.
+var GraphFormats = []GraphFormat{ //nolint:gochecknoglobals // Constant list.
+	GraphFormatDot,
+	GraphFormatMermaid,
+	GraphFormatJSON,
+}
+
+type (
+	// GraphNode is a target node in the exported JSON graph.
+	GraphNode struct {
+		ID        target.ID   `json:"id"`
+		Component string      `json:"component"`
+		Stage     stage.Stage `json:"stage"`
+		Priority  int         `json:"priority"`
+
+		Changed             bool `json:"changed"`
+		ChangedByDependency bool `json:"changedByDependency"`
+
+		// The targets depending on this one (in execution direction).
+		Forward []target.ID `json:"forward"`
+		// The targets this one depends on.
+		Backward []target.ID `json:"backward"`
+		// The targets this one runs after (weak ordering).
+		After []target.ID `json:"after"`
+	}
+
+	// Graph is the exported JSON graph.
+	Graph struct {
+		Nodes []GraphNode `json:"nodes"`
+	}
+)
+
+// NewGraph returns the graph over all nodes in `nodes`.
+// Edges to nodes not in `nodes` are dropped.
+// Nodes are sorted by descending priority and id.
+func NewGraph(nodes TargetNodeMap) (g Graph) {
+	edges := func(ns []*TargetNode) []target.ID {
+		ids := []target.ID{}
+		for _, n := range ns {
+			if _, exists := nodes[n.Target.ID]; exists {
+				ids = append(ids, n.Target.ID)
+			}
+		}
+		slices.Sort(ids)
+
+		return ids
+	}
+
+	for _, n := range sortedNodes(nodes) {
+		g.Nodes = append(g.Nodes,
+			GraphNode{
+				ID:                  n.Target.ID,
+				Component:           n.Comp.Name(),
+				Stage:               n.Target.Stage,
+				Priority:            n.Priority,
+				Changed:             n.Inputs.IsChanged(),
+				ChangedByDependency: n.Inputs.ChangedByDependency,
+				Forward:             edges(n.Forward),
+				Backward:            edges(n.Backward),
+				After:               edges(n.After),
+			})
+	}
+
+	return g
+}
+
+// ExportGraph writes the graph over all nodes in `nodes` in format `format` to `w`.
+// Edges point in execution direction (from a dependency to its dependent),
+// the weak ordering (`after`) is drawn dashed.
+// Changed targets are coloured differently than unchanged ones.
+func ExportGraph(w io.Writer, nodes TargetNodeMap, format GraphFormat) error {
+	g := NewGraph(nodes)
+
+	switch format {
+	case GraphFormatDot:
+		return g.writeDot(w)
+	case GraphFormatMermaid:
+		return g.writeMermaid(w)
+	case GraphFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(g)
+	default:
+		return errors.New("unknown graph format '%v', use one of '%q'", format, GraphFormats)
+	}
+}
+
+func (g *Graph) writeDot(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph targets {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=\"rounded,filled\"];\n")
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+
+		color := "lightgrey"
+		if n.Changed {
+			color = "orange"
+		}
+
+		fmt.Fprintf(&sb, "  %q [label=%q, fillcolor=%q];\n",
+			n.ID, n.label("\n"), color)
+	}
+
+	for i := range g.Nodes {
+		for _, f := range g.Nodes[i].Forward {
+			fmt.Fprintf(&sb, "  %q -> %q;\n", g.Nodes[i].ID, f)
+		}
+
+		for _, a := range g.Nodes[i].After {
+			fmt.Fprintf(&sb, "  %q -> %q [style=dashed];\n", a, g.Nodes[i].ID)
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func (g *Graph) writeMermaid(w io.Writer) error {
+	// Mermaid node ids cannot contain `::`.
+	ids := make(map[target.ID]string, len(g.Nodes))
+	for i := range g.Nodes {
+		ids[g.Nodes[i].ID] = fmt.Sprintf("n%v", i)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+
+		class := "unchanged"
+		if n.Changed {
+			class = "changed"
+		}
+
+		fmt.Fprintf(&sb, "  %v[\"%v\"]:::%v\n",
+			ids[n.ID], strings.ReplaceAll(n.label("<br/>"), "\"", "#quot;"), class)
+	}
+
+	for i := range g.Nodes {
+		for _, f := range g.Nodes[i].Forward {
+			fmt.Fprintf(&sb, "  %v --> %v\n", ids[g.Nodes[i].ID], ids[f])
+		}
+
+		for _, a := range g.Nodes[i].After {
+			fmt.Fprintf(&sb, "  %v -.-> %v\n", ids[a], ids[g.Nodes[i].ID])
+		}
+	}
+
+	sb.WriteString("  classDef changed fill:#ffa500\n")
+	sb.WriteString("  classDef unchanged fill:#d3d3d3\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// label returns the node label with lines separated by `sep`.
+func (n *GraphNode) label(sep string) string {
+	return strings.Join([]string{
+		n.ID.String(),
+		fmt.Sprintf("stage: %v, prio: %v", n.Stage, n.Priority),
+	}, sep)
+}
+
+// sortedNodes returns all nodes sorted by descending priority and id.
+func sortedNodes(nodes TargetNodeMap) []*TargetNode {
+	return slices.SortedFunc(maps.Values(nodes), func(a, b *TargetNode) int {
+		return cmp.Or(
+			cmp.Compare(b.Priority, a.Priority),
+			cmp.Compare(a.Target.ID, b.Target.ID))
+	})
+}
@@ -0,0 +1,45 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportGraph(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(
+		generateFingerprintComps(t, root), root,
+		WithInputChanges([]string{"a/src/main.go"}))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportGraph(&buf, nodes, GraphFormatDot))
+	assert.Contains(t, buf.String(), `"a::build" -> "b::build";`)
+	assert.Contains(t, buf.String(), `fillcolor="orange"`)
+
+	buf.Reset()
+	require.NoError(t, ExportGraph(&buf, nodes, GraphFormatMermaid))
+	assert.Contains(t, buf.String(), "n0 --> n1")
+	assert.Contains(t, buf.String(), `n0["a::build<br/>stage: build, prio: 1"]:::changed`)
+
+	buf.Reset()
+	require.NoError(t, ExportGraph(&buf, nodes, GraphFormatJSON))
+	var g Graph
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &g))
+	require.Len(t, g.Nodes, 2)
+	assert.Equal(t, []target.ID{"b::build"}, g.Nodes[0].Forward)
+	assert.Equal(t, []target.ID{"a::build"}, g.Nodes[1].Backward)
+	assert.True(t, g.Nodes[1].ChangedByDependency)
+
+	require.Error(t, ExportGraph(&buf, nodes, "svg"))
+}
@@ -8,4 +8,7 @@ type InputChanges struct {
 	// Paths which have changed (this list may not represent all paths)
 	// because of early returns.
 	Changes []string
+
+	// The reason of the first matched path.
+	Reason *ChangeReason
 }
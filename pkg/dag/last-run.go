@@ -0,0 +1,106 @@
+package dag
+
+import (
+	"encoding/json"
+	stderr "errors"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+// lastRunFileName is the name of the file storing the last run.
+const lastRunFileName = "last-run.json"
+
+// LastRunFile returns the file in the output directory `outDir` where
+// the runner statuses of the last execution are stored (see [WithLastRunFile]).
+func LastRunFile(outDir string) string {
+	return path.Join(outDir, lastRunFileName)
+}
+
+// LoadRerunTargets loads the last run stored in `file` (see [WithLastRunFile])
+// and returns all targets (sorted) which have a failed, not run or cancelled runner.
+func LoadRerunTargets(file string) ([]target.ID, error) {
+	r, err := loadLastRun(file)
+	if stderr.Is(err, os.ErrNotExist) {
+		return nil, errors.New("there is no last run stored in '%v'", file)
+	} else if err != nil {
+		return nil, err
+	}
+
+	rerun := []string{
+		ExecStatus(ExecStatusFailed).String(),
+		ExecStatus(ExecStatusNotRun).String(),
+		ExecStatus(ExecStatusCancelled).String(),
+	}
+
+	var ids []target.ID
+	for i := range r.Runners {
+		if slices.Contains(rerun, r.Runners[i].Status) {
+			ids = append(ids, r.Runners[i].TargetID)
+		}
+	}
+
+	slices.Sort(ids)
+
+	return slices.Compact(ids), nil
+}
+
+// loadLastRun loads the last run stored in `file`.
+func loadLastRun(file string) (r Report, err error) {
+	data, err := os.ReadFile(file)
+	if stderr.Is(err, os.ErrNotExist) {
+		return r, err
+	} else if err != nil {
+		return r, errors.AddContext(err, "could not read last run '%v'", file)
+	}
+
+	err = json.Unmarshal(data, &r)
+	if err != nil {
+		return r, errors.AddContext(err, "could not parse last run '%v'", file)
+	}
+
+	return r, nil
+}
+
+// storeLastRun merges the runner statuses of all `targets` into the last run
+// stored in `file`: The runners of all `targets` are replaced, the runners of
+// other targets (e.g. of another stage) are kept, such that their failures
+// can still be rerun.
+func storeLastRun(file string, targets TargetNodeMap) error {
+	last, err := loadLastRun(file)
+	if err != nil && !stderr.Is(err, os.ErrNotExist) {
+		log.WarnE(err, "Replacing the unreadable last run.", "file", file)
+	}
+
+	var statuses RunnerStatuses
+	for _, n := range targets {
+		statuses = append(statuses, n.Execution.Runners...)
+	}
+	r := NewReport(statuses)
+
+	for i := range last.Runners {
+		if _, exists := targets[last.Runners[i].TargetID]; !exists {
+			r.Runners = append(r.Runners, last.Runners[i])
+		}
+	}
+	r.sort()
+
+	err = os.MkdirAll(path.Dir(file), fs.DefaultPermissionsDir)
+	if err != nil {
+		return errors.AddContext(err, "could not create directory for last run '%v'", file)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.AddContext(err, "could not encode last run '%v'", file)
+	}
+
+	err = os.WriteFile(file, append(data, '\n'), fs.DefaultPermissionsFile)
+
+	return errors.AddContext(err, "could not write last run '%v'", file)
+}
@@ -0,0 +1,122 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/common/set"
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRerunTargets(t *testing.T) {
+	t.Parallel()
+
+	statuses := append(testStatuses(),
+		&RunnerStatus{Status: ExecStatusSuccess, CompName: "c", TargetID: "c::build"},
+		&RunnerStatus{Status: ExecStatusCancelled, CompName: "d", TargetID: "d::build"},
+		&RunnerStatus{Status: ExecStatusUpToDate, CompName: "e", TargetID: "e::build"},
+	)
+
+	file := LastRunFile(t.TempDir())
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, WriteReport(f, statuses, ReportFormatJSON))
+	require.NoError(t, f.Close())
+
+	ids, err := LoadRerunTargets(file)
+	require.NoError(t, err)
+	assert.Equal(t, []target.ID{"a::build", "b::build", "d::build"}, ids)
+
+	require.NoError(t, os.WriteFile(file, []byte("{"), fs.DefaultPermissionsFile))
+	_, err = LoadRerunTargets(file)
+	require.ErrorContains(t, err, "could not parse")
+
+	_, err = LoadRerunTargets(LastRunFile(t.TempDir()))
+	require.ErrorContains(t, err, "no last run")
+}
+
+func TestTargetSelectionRestrict(t *testing.T) {
+	t.Parallel()
+
+	// Restrict the stage selection: `3::build` runs with its dependencies.
+	comps := generateChainComps(t)
+	targets, _, e := DefineExecutionOrder(
+		comps,
+		rootDir,
+		WithTargetsByStageFromComponents(comps[2:], "build"),
+		WithTargetSelectionRestrict("3::build", "1::build"),
+	)
+	require.NoError(t, e)
+	assert.Len(t, targets, 3)
+	assert.NotContains(t, targets, target.ID("4::build"))
+
+	// Without a prior selection the restriction is the selection.
+	targets, _, e = DefineExecutionOrder(comps, rootDir, WithTargetSelectionRestrict("2::build"))
+	require.NoError(t, e)
+	assert.Len(t, targets, 2)
+
+	// Nothing left.
+	sel := set.NewUnordered[target.ID]("4::build")
+	targets, _, e = DefineExecutionOrder(
+		comps,
+		rootDir,
+		WithTargetSelection(&sel),
+		WithTargetSelectionRestrict("1::build"),
+	)
+	require.NoError(t, e)
+	assert.Empty(t, targets)
+}
+
+func TestExecuteLastRunMerge(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	file := LastRunFile(t.TempDir())
+	comps := []*component.Component{
+		newOutputNode(t, root, "a").Comp,
+		newOutputNode(t, root, "b").Comp,
+	}
+
+	fail := true
+	f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+		if fail && ctx.Target() == "a::build" {
+			return errors.New("failure")
+		}
+
+		return nil
+	})
+
+	execute := func(id target.ID) error {
+		sel := set.NewUnordered(id)
+		targets, prios, err := DefineExecutionOrder(comps, root, WithTargetSelection(&sel))
+		require.NoError(t, err)
+
+		return Execute(targets, prios, f, nil, nil, root, false,
+			WithForce(true), WithLastRunFile(file))
+	}
+
+	// Executing other targets keeps the failure.
+	require.Error(t, execute("a::build"))
+	require.NoError(t, execute("b::build"))
+
+	ids, err := LoadRerunTargets(file)
+	require.NoError(t, err)
+	assert.Equal(t, []target.ID{"a::build"}, ids)
+
+	// The successful rerun replaces the failure.
+	fail = false
+	require.NoError(t, execute("a::build"))
+
+	ids, err = LoadRerunTargets(file)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
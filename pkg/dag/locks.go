@@ -0,0 +1,50 @@
+package dag
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+// targetLocks are the named locks [target.Config.Locks] of all targets.
+type targetLocks map[string]*sync.Mutex
+
+// newTargetLocks creates all named locks of targets `nodes`.
+func newTargetLocks(nodes TargetNodeMap) targetLocks {
+	locks := targetLocks{}
+
+	for _, n := range nodes {
+		for _, l := range n.Target.Locks {
+			if _, exists := locks[l]; !exists {
+				locks[l] = &sync.Mutex{}
+			}
+		}
+	}
+
+	return locks
+}
+
+// lock acquires all locks `names` for target `id`.
+// The locks are acquired in sorted order to not deadlock.
+func (l targetLocks) lock(id target.ID, names []string) {
+	names = slices.Sorted(slices.Values(names))
+	names = slices.Compact(names)
+
+	for _, n := range names {
+		log.Debug("Acquire lock.", "target", id, "lock", n)
+		l[n].Lock()
+	}
+}
+
+// unlock releases all locks `names` for target `id`.
+func (l targetLocks) unlock(id target.ID, names []string) {
+	names = slices.Sorted(slices.Values(names))
+	names = slices.Compact(names)
+
+	for _, n := range slices.Backward(names) {
+		log.Debug("Release lock.", "target", id, "lock", n)
+		l[n].Unlock()
+	}
+}
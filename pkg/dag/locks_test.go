@@ -0,0 +1,144 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetLocks(t *testing.T) {
+	t.Parallel()
+
+	nodes := TargetNodeMap{
+		"a": {Target: &target.Config{ID: "a", Locks: []string{"db", "port"}}},
+		"b": {Target: &target.Config{ID: "b", Locks: []string{"port", "db", "db"}}},
+		"c": {Target: &target.Config{ID: "c", Locks: []string{"db"}}},
+	}
+	locks := newTargetLocks(nodes)
+	assert.Len(t, locks, 2)
+
+	var running atomic.Int32
+	var maxRunning atomic.Int32
+	var wg sync.WaitGroup
+
+	for range 10 {
+		for _, n := range nodes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				locks.lock(n.Target.ID, n.Target.Locks)
+				defer locks.unlock(n.Target.ID, n.Target.Locks)
+
+				r := running.Add(1)
+				if r > maxRunning.Load() {
+					maxRunning.Store(r)
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+			}()
+		}
+	}
+
+	wg.Wait()
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestExecuteConcurrentLocks(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	// Independent targets sharing a lock: the first step removes
+	// the shared output and the last step writes it again, such that
+	// the output check fails if another target runs in between.
+	targets := map[string]*target.Config{}
+	for i := range 4 {
+		targets[fmt.Sprintf("t%v", i)] = &target.Config{
+			Locks:   []string{"db"},
+			Outputs: []target.Output{"shared"},
+			Steps:   []step.Config{{RunnerID: "test"}, {RunnerID: "test"}},
+		}
+	}
+	conf := &component.Config{Name: "a", Language: "go", Targets: targets}
+	require.NoError(t, conf.Init())
+	comp := component.NewComponent(conf, root, "", "")
+
+	nodes, prios, err := DefineExecutionOrder([]*component.Component{&comp}, root)
+	require.NoError(t, err)
+
+	shared := path.Join(root, "shared")
+	var mutex sync.Mutex
+	var events []target.ID
+
+	f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+		mutex.Lock()
+		events = append(events, ctx.Target())
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		if ctx.Step() == 0 {
+			return os.RemoveAll(shared)
+		}
+
+		return os.WriteFile(shared, []byte(ctx.Target()), fs.DefaultPermissionsFile)
+	})
+
+	err = Execute(nodes, prios, f, nil, nil, root, true, WithForce(true), WithJobs(4))
+	require.NoError(t, err)
+
+	// The steps of the targets never interleave.
+	require.Len(t, events, 8)
+	for i := 0; i < len(events); i += 2 {
+		assert.Equal(t, events[i], events[i+1], "events %v", events)
+	}
+}
+
+func TestExecuteLocksTimeout(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	// The target timeout starts after the locks are acquired,
+	// waiting for the other target does not count.
+	targets := map[string]*target.Config{}
+	for i := range 2 {
+		targets[fmt.Sprintf("t%v", i)] = &target.Config{
+			Locks:   []string{"db"},
+			Timeout: 300 * time.Millisecond,
+			Steps:   []step.Config{{RunnerID: "test"}},
+		}
+	}
+	conf := &component.Config{Name: "a", Language: "go", Targets: targets}
+	require.NoError(t, conf.Init())
+	comp := component.NewComponent(conf, root, "", "")
+
+	nodes, prios, err := DefineExecutionOrder([]*component.Component{&comp}, root)
+	require.NoError(t, err)
+
+	f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			return nil
+		case <-ctx.Ctx().Done():
+			return ctx.Ctx().Err()
+		}
+	})
+
+	err = Execute(nodes, prios, f, nil, nil, root, true, WithForce(true), WithJobs(2))
+	require.NoError(t, err)
+}
@@ -1,7 +1,10 @@
 package dag
 
 import (
+	"time"
+
 	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/input"
 	"github.com/sdsc-ordes/quitsh/pkg/component/target"
 	"github.com/sdsc-ordes/quitsh/pkg/log"
 )
@@ -27,9 +30,19 @@ type (
 		// Backward in execution direction.
 		Backward []*TargetNode
 
+		// All nodes after which this node runs (weak ordering, see [target.Config.After]).
+		// Only contains nodes reached from the target selection.
+		// Backward in execution direction.
+		After []*TargetNode
+
 		// Tracking inputs on this node.
 		Inputs TargetNodeChanges
 
+		// The fingerprint of this target (only set when computed).
+		Fingerprint Fingerprint
+		// The registry the inputs on this node are resolved against.
+		registry *inputRegistry
+
 		// Tracking execution.
 		Execution TargetExecStatus
 	}
@@ -38,8 +51,17 @@ type (
 		// Marking the target to not run and skip.
 		Cancel bool
 
+		// Marking the target as up-to-date, it does not need to run.
+		UpToDate bool
+
+		// Marking the target as restored from the cache, it does not need to run.
+		Restored bool
+
 		// All runner statuses for the steps.
 		Runners RunnerStatuses
+
+		// The deadline of the target timeout, set when the target starts.
+		deadline time.Time
 	}
 
 	TargetNodeChanges struct {
@@ -57,6 +79,28 @@ type (
 
 		// Changed paths by all parents.
 		AccumulatedPaths []string
+
+		// The reason why the target changed by its own inputs.
+		// It is `nil` if not changed by its own inputs or if no
+		// changed paths were given (all targets are changed).
+		Reason *ChangeReason
+
+		// The first dependency which propagated its change to this target
+		// (if `ChangedByDependency`).
+		ChangedBy *TargetNode
+	}
+
+	// ChangeReason explains why a target changed by its own inputs.
+	ChangeReason struct {
+		// The input set which matched.
+		InputID input.ID `json:"input"`
+
+		// The include pattern which matched the path.
+		// Empty for component inputs which match all paths in the component's root.
+		Pattern string `json:"pattern,omitempty"`
+
+		// The changed path which matched.
+		Path string `json:"path"`
 	}
 )
 
@@ -66,11 +110,27 @@ func (i *TargetNodeChanges) IsChanged() bool {
 }
 
 // Propagate propagates change state from `other` to `i`.
+// A change is propagated transitively, also if `other` is only changed by
+// a dependency.
 func (i *TargetNodeChanges) Propagate(other *TargetNodeChanges) {
-	i.ChangedByDependency = i.ChangedByDependency || other.Changed
+	i.ChangedByDependency = i.ChangedByDependency || other.IsChanged()
 	i.AccumulatedPaths = append(i.AccumulatedPaths, other.AccumulatedPaths...)
 }
 
+// ChangeChain returns the chain of upstream targets which carried the change to
+// this node, starting at the direct dependency and ending at the target which
+// changed by its own inputs. It is empty if the node is not changed by a dependency.
+func (n *TargetNode) ChangeChain() (chain []*TargetNode) {
+	for c := n.Inputs.ChangedBy; c != nil; c = c.Inputs.ChangedBy {
+		chain = append(chain, c)
+		if c.Inputs.Changed {
+			break
+		}
+	}
+
+	return chain
+}
+
 // All returns all changes, accumulated ones from depend targets
 // as well own changed paths.
 func (i *TargetNodeChanges) All() []string {
@@ -92,7 +152,7 @@ func (e *TargetExecStatus) AddRunnerStatus() *RunnerStatus {
 // Status determines the overall status of the target.
 func (n *TargetNode) Status() ExecStatus {
 	for _, r := range n.Execution.Runners {
-		if r.Status != ExecStatusSuccess {
+		if !r.Status.IsSuccess() {
 			return ExecStatusFailed
 		}
 	}
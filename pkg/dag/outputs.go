@@ -0,0 +1,211 @@
+package dag
+
+import (
+	stderr "errors"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/sdsc-ordes/quitsh/pkg/cache"
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+// Names of the base directories outputs are relative to.
+const (
+	outputDirRoot = "root"
+	outputDirOut  = "out"
+)
+
+// OutputDirs returns the base directories of the outputs of targets
+// on component `comp`.
+func OutputDirs(comp *component.Component) cache.Dirs {
+	return cache.Dirs{
+		outputDirRoot: comp.Root(),
+		outputDirOut:  comp.OutDir(),
+	}
+}
+
+// ResolveOutputs resolves the output patterns `outputs` on component `comp`
+// to existing paths. The patterns which did not match anything are
+// returned in `missing`.
+func ResolveOutputs(
+	comp *component.Component,
+	outputs []target.Output,
+) (entries []cache.Entry, missing []target.Output, err error) {
+	dirs := OutputDirs(comp)
+
+	for _, o := range outputs {
+		dir := outputDirRoot
+		if o.IsOutDir() {
+			dir = outputDirOut
+		}
+
+		matches, e := doublestar.Glob(
+			os.DirFS(dirs[dir]),
+			o.Pattern(),
+			doublestar.WithFailOnIOErrors(),
+			doublestar.WithNoFollow())
+		if e != nil && !stderr.Is(e, os.ErrNotExist) {
+			return nil, nil, errors.AddContext(e, "could not resolve output '%v'", o)
+		}
+
+		if len(matches) == 0 {
+			missing = append(missing, o)
+
+			continue
+		}
+
+		for _, m := range matches {
+			entries = append(entries, cache.Entry{Dir: dir, Path: m})
+		}
+	}
+
+	return entries, missing, nil
+}
+
+// ResolveOutputPaths resolves the output patterns `outputs` on component `comp`
+// to existing absolute paths.
+func ResolveOutputPaths(
+	comp *component.Component,
+	outputs []target.Output,
+) (paths []string, missing []target.Output, err error) {
+	entries, missing, err := ResolveOutputs(comp, outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dirs := OutputDirs(comp)
+	for _, e := range entries {
+		paths = append(paths, path.Join(dirs[e.Dir], e.Path))
+	}
+
+	return paths, missing, nil
+}
+
+// IsOutput reports if the absolute path `p` is an output of this target,
+// i.e. it matches one of the output patterns or lies below a match.
+func (n *TargetNode) IsOutput(p string) bool {
+	return isOutput(n.Comp, n.Target.Outputs, p)
+}
+
+// isComponentOutput reports if the absolute path `p` is an output of
+// any target on component `comp` (see [TargetNode.IsOutput]).
+func isComponentOutput(comp *component.Component, p string) bool {
+	for _, t := range comp.Config().Targets {
+		if isOutput(comp, t.Outputs, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isOutput reports if the absolute path `p` matches one of the output
+// patterns `outputs` on component `comp` or lies below a match.
+func isOutput(comp *component.Component, outputs []target.Output, p string) bool {
+	dirs := OutputDirs(comp)
+
+	for _, o := range outputs {
+		dir := outputDirRoot
+		if o.IsOutDir() {
+			dir = outputDirOut
+		}
+
+		rel, err := filepath.Rel(dirs[dir], p)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+
+		for sub := filepath.ToSlash(rel); sub != "." && sub != "/"; sub = path.Dir(sub) {
+			if matched, _ := doublestar.Match(o.Pattern(), sub); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// DropOutputChanges returns the changed absolute paths `paths` without the outputs
+// of all targets `nodes` (see [TargetNode.IsOutput]), such that targets which write
+// their outputs do not count as changed by them.
+func DropOutputChanges(nodes TargetNodeMap, paths []string) []string {
+	return slices.DeleteFunc(slices.Clone(paths), func(p string) bool {
+		for _, n := range nodes {
+			if n.IsOutput(p) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// outputDirs returns the base directories of the outputs of this target.
+func (n *TargetNode) outputDirs() cache.Dirs {
+	return OutputDirs(n.Comp)
+}
+
+// ResolveOutputs resolves the outputs [target.Config.Outputs] of this target
+// (see [ResolveOutputs]).
+func (n *TargetNode) ResolveOutputs() (entries []cache.Entry, missing []target.Output, err error) {
+	entries, missing, err = ResolveOutputs(n.Comp, n.Target.Outputs)
+	if err != nil {
+		return nil, nil, errors.AddContext(err,
+			"could not resolve outputs of target '%v'", n.Target.ID)
+	}
+
+	return entries, missing, nil
+}
+
+// DependencyOutputs returns the resolved outputs (absolute paths)
+// of all direct dependencies of this target.
+func (n *TargetNode) DependencyOutputs() (map[target.ID][]string, error) {
+	outputs := make(map[target.ID][]string, len(n.Backward))
+
+	for _, d := range n.Backward {
+		if len(d.Target.Outputs) == 0 {
+			continue
+		}
+
+		paths, _, err := ResolveOutputPaths(d.Comp, d.Target.Outputs)
+		if err != nil {
+			return nil, errors.AddContext(err,
+				"could not resolve outputs of dependency '%v'", d.Target.ID)
+		}
+
+		outputs[d.Target.ID] = paths
+	}
+
+	return outputs, nil
+}
+
+// checkOutputs validates that all outputs of this target exist
+// after it ran successfully. Otherwise the status of the last runner
+// is set to failed.
+func (n *TargetNode) checkOutputs() {
+	if len(n.Target.Outputs) == 0 ||
+		n.Execution.UpToDate || n.Execution.Restored ||
+		len(n.Execution.Runners) == 0 ||
+		n.Status() != ExecStatusSuccess {
+		return
+	}
+
+	_, missing, err := n.ResolveOutputs()
+	if err == nil && len(missing) != 0 {
+		err = errors.New(
+			"declared outputs '%q' of target '%v' do not exist after successful run",
+			missing, n.Target.ID)
+	}
+
+	if err != nil {
+		last := n.Execution.Runners[len(n.Execution.Runners)-1]
+		last.Status = ExecStatusFailed
+		last.Error = err
+	}
+}
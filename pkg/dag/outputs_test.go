@@ -0,0 +1,102 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputs(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+
+	a := nodes["a::build"]
+	b := nodes["b::build"]
+	a.Target.Outputs = []target.Output{"out:build/bin/*"}
+
+	// Successful run without outputs fails.
+	status := a.Execution.AddRunnerStatus()
+	status.Status = ExecStatusSuccess
+	a.checkOutputs()
+	assert.EqualValues(t, ExecStatusFailed, status.Status)
+	require.Error(t, status.Error)
+
+	// Produce the outputs.
+	require.NoError(t, os.MkdirAll(a.Comp.OutBuildDir("bin"), fs.DefaultPermissionsDir))
+	require.NoError(t,
+		os.WriteFile(a.Comp.OutBuildDir("bin", "a"), []byte("bin"), fs.DefaultPermissionsFile))
+
+	*status = RunnerStatus{Status: ExecStatusSuccess}
+	a.checkOutputs()
+	assert.EqualValues(t, ExecStatusSuccess, status.Status)
+	require.NoError(t, status.Error)
+
+	outs, err := b.DependencyOutputs()
+	require.NoError(t, err)
+	assert.Equal(t,
+		map[target.ID][]string{"a::build": {a.Comp.OutBuildDir("bin", "a")}},
+		outs)
+}
+
+func TestDropOutputChanges(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	// Target `a::build` generates a root-relative output within its inputs.
+	comps := generateFingerprintComps(t, root)
+	tgtA := comps[0].Config().Targets["build"]
+	tgtA.Outputs = []target.Output{"src/gen"}
+	tgtA.Steps = []step.Config{{RunnerID: "test"}}
+	comps[1].Config().Targets["build"].Steps = []step.Config{{RunnerID: "test"}}
+
+	gen := path.Join(root, "a/src/gen/gen.go")
+	f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+		if ctx.Target() != "a::build" {
+			return nil
+		}
+		e := os.MkdirAll(path.Dir(gen), fs.DefaultPermissionsDir)
+		if e != nil {
+			return e
+		}
+
+		return os.WriteFile(gen, []byte("package gen"), fs.DefaultPermissionsFile)
+	})
+
+	targets, prios, err := DefineExecutionOrder(comps, root)
+	require.NoError(t, err)
+	require.NoError(t, Execute(targets, prios, f, nil, nil, root, false, WithForce(true)))
+
+	assert.True(t, targets["a::build"].IsOutput(gen))
+	assert.False(t, targets["a::build"].IsOutput(path.Join(root, "a/src/main.go")))
+	assert.False(t, targets["b::build"].IsOutput(gen))
+
+	// The written output is an input and would trigger the target again.
+	affected, _, err := DefineExecutionOrder(
+		generateFingerprintComps(t, root), root, WithInputChanges([]string{gen}))
+	require.NoError(t, err)
+	assert.Contains(t, affected, target.ID("a::build"))
+
+	changes := DropOutputChanges(targets, []string{gen})
+	assert.Empty(t, changes)
+	affected, _, err = DefineExecutionOrder(
+		generateFingerprintComps(t, root), root, WithInputChanges(changes))
+	require.NoError(t, err)
+	assert.Empty(t, affected)
+
+	// Other changes still do.
+	changes = DropOutputChanges(targets, []string{gen, path.Join(root, "a/src/main.go")})
+	assert.Len(t, changes, 1)
+}
@@ -0,0 +1,213 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/exec/nix"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+	"github.com/sdsc-ordes/quitsh/pkg/runner/factory"
+)
+
+type (
+	// Plan is the resolved execution plan of the DAG (see [NewPlan]).
+	Plan struct {
+		// All targets in execution order (descending priority).
+		Targets []PlanTarget `json:"targets"`
+	}
+
+	// PlanTarget is a target in the execution plan.
+	PlanTarget struct {
+		ID        target.ID   `json:"id"`
+		Component string      `json:"component"`
+		Stage     stage.Stage `json:"stage"`
+		Priority  int         `json:"priority"`
+
+		Dependencies []target.ID `json:"dependencies,omitempty"`
+		// The executed targets this one runs after (weak ordering).
+		After []target.ID `json:"after,omitempty"`
+
+		// The change status of the target (see [TargetNodeChanges]).
+		Changed             bool     `json:"changed"`
+		ChangedByDependency bool     `json:"changedByDependency"`
+		ChangedPaths        []string `json:"changedPaths,omitempty"`
+
+		// If the steps run concurrently (see [target.Config.Parallel]).
+		Parallel bool `json:"parallel,omitempty"`
+
+		Steps []PlanStep `json:"steps"`
+	}
+
+	// PlanStep is a step of a target in the execution plan.
+	PlanStep struct {
+		Index step.Index `json:"index"`
+
+		// If the step is excluded by its tag expression.
+		Excluded bool `json:"excluded"`
+
+		// If the step runs even if earlier steps failed.
+		Always bool `json:"always,omitempty"`
+
+		Runners []PlanRunner `json:"runners,omitempty"`
+	}
+
+	// PlanRunner is a resolved runner of a step in the execution plan.
+	PlanRunner struct {
+		ID runner.RegisterID `json:"id"`
+
+		// The toolchain the runner runs in.
+		Toolchain string `json:"toolchain"`
+		// If the runner is dispatched over the toolchain dispatcher.
+		Dispatched bool `json:"dispatched"`
+	}
+)
+
+// NewPlan resolves the execution plan of the targets in `prios` without
+// executing anything. The runners are resolved over the factory `runnerFactory`.
+// If `dispatch` is set, runners are reported as dispatched if their toolchain
+// is not the current one. Only [WithTags] is considered from the options.
+func NewPlan(
+	prios Priorities,
+	runnerFactory factory.IFactory,
+	dispatch bool,
+	opts ...ExecuteOption,
+) (plan Plan, err error) {
+	opt := execOption{}
+	if e := opt.Apply(opts...); e != nil {
+		return plan, e
+	}
+
+	planned := make(map[target.ID]bool)
+	for _, prio := range prios {
+		for _, node := range prio.Nodes {
+			planned[node.Target.ID] = true
+		}
+	}
+
+	for _, prio := range prios {
+		for _, node := range prio.Nodes {
+			t := PlanTarget{
+				ID:                  node.Target.ID,
+				Component:           node.Comp.Name(),
+				Stage:               node.Target.Stage,
+				Priority:            node.Priority,
+				Dependencies:        node.Target.Dependencies,
+				Parallel:            node.Target.Parallel,
+				Changed:             node.Inputs.IsChanged(),
+				ChangedByDependency: node.Inputs.ChangedByDependency,
+				ChangedPaths:        node.Inputs.All(),
+			}
+
+			for _, a := range node.After {
+				if planned[a.Target.ID] {
+					t.After = append(t.After, a.Target.ID)
+				}
+			}
+
+			for i := range node.Target.Steps {
+				s := &node.Target.Steps[i]
+				pS := PlanStep{Index: s.Index, Always: s.Always}
+
+				if !s.Include.TagExpr.Matches(opt.Tags) {
+					pS.Excluded = true
+					t.Steps = append(t.Steps, pS)
+
+					continue
+				}
+
+				runners, e := createRunners(runnerFactory, node, s)
+				if e != nil {
+					err = errors.Combine(err, e)
+
+					continue
+				}
+
+				for _, r := range runners {
+					pS.Runners = append(pS.Runners,
+						PlanRunner{
+							ID:         r.RunnerID,
+							Toolchain:  r.Toolchain,
+							Dispatched: dispatch && !nix.HaveToolchain(r.Toolchain),
+						})
+				}
+
+				t.Steps = append(t.Steps, pS)
+			}
+
+			plan.Targets = append(plan.Targets, t)
+		}
+	}
+
+	if err != nil {
+		return plan, errors.AddContext(err, "failed to resolve the execution plan")
+	}
+
+	return plan, nil
+}
+
+// Format formats the plan human-readable.
+func (p *Plan) Format() string {
+	var sb strings.Builder
+	sb.WriteString("Execution Plan:\n")
+
+	for i := range p.Targets {
+		t := &p.Targets[i]
+
+		fmt.Fprintf(&sb, "• Target: '%v' (component: '%v', stage: '%v', priority: '%v')\n",
+			t.ID, t.Component, t.Stage, t.Priority)
+
+		if len(t.Dependencies) != 0 {
+			fmt.Fprintf(&sb, "  Depends: %q\n", t.Dependencies)
+		}
+
+		if t.Parallel {
+			sb.WriteString("  Parallel steps: yes\n")
+		}
+
+		if len(t.After) != 0 {
+			fmt.Fprintf(&sb, "  After: %q\n", t.After)
+		}
+
+		switch {
+		case t.ChangedByDependency:
+			sb.WriteString("  Changed: by dependency\n")
+		case t.Changed:
+			sb.WriteString("  Changed: yes\n")
+		default:
+			sb.WriteString("  Changed: no\n")
+		}
+
+		for _, path := range t.ChangedPaths {
+			fmt.Fprintf(&sb, "    - '%v'\n", path)
+		}
+
+		for _, s := range t.Steps {
+			if s.Excluded {
+				fmt.Fprintf(&sb, "  • Step '%v': excluded by tags\n", s.Index)
+
+				continue
+			}
+
+			if s.Always {
+				fmt.Fprintf(&sb, "  • Step '%v' (always):\n", s.Index)
+			} else {
+				fmt.Fprintf(&sb, "  • Step '%v':\n", s.Index)
+			}
+			for _, r := range s.Runners {
+				dispatch := "in-process"
+				if r.Dispatched {
+					dispatch = "dispatched"
+				}
+
+				fmt.Fprintf(&sb, "    • Runner: '%v', toolchain: '%v' (%v)\n",
+					r.ID, r.Toolchain, dispatch)
+			}
+		}
+	}
+
+	return sb.String()
+}
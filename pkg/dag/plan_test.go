@@ -0,0 +1,87 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+	"github.com/sdsc-ordes/quitsh/pkg/runner/factory"
+	"github.com/sdsc-ordes/quitsh/pkg/tags"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRunner struct {
+	id  runner.RegisterID
+	run func(ctx runner.IContext) error
+}
+
+func (r *testRunner) ID() runner.RegisterID { return r.id }
+
+func (r *testRunner) Run(ctx runner.IContext) error {
+	if r.run == nil {
+		return nil
+	}
+
+	return r.run(ctx)
+}
+
+// newTestFactory returns a factory with the runner `id` registered
+// in toolchain `nix` which executes `run`.
+func newTestFactory(
+	t *testing.T,
+	id runner.RegisterID,
+	run func(ctx runner.IContext) error,
+) factory.IFactory {
+	f := factory.NewFactory(nil)
+	require.NoError(t, f.Register(id, runner.RunnerData{
+		Creator: func(step.AuxConfig) (runner.IRunner, error) {
+			return &testRunner{id: id, run: run}, nil
+		},
+		DefaultToolchain: "nix",
+	}))
+
+	return f
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	comps := generateFingerprintComps(t, root)
+	tgtA := comps[0].Config().Targets["build"]
+	tgtA.Steps = []step.Config{{Index: 0, RunnerID: "test"}, {Index: 1, RunnerID: "test"}}
+
+	var err error
+	tgtA.Steps[1].Include.TagExpr, err = tags.NewExpr("ci")
+	require.NoError(t, err)
+
+	_, prios, err := DefineExecutionOrder(comps, root)
+	require.NoError(t, err)
+
+	plan, err := NewPlan(prios, newTestFactory(t, "test", nil), true)
+	require.NoError(t, err)
+	require.Len(t, plan.Targets, 2)
+
+	a := plan.Targets[0]
+	assert.EqualValues(t, "a::build", a.ID)
+	assert.True(t, a.Changed)
+	require.Len(t, a.Steps, 2)
+	assert.Equal(t,
+		[]PlanRunner{{ID: "test", Toolchain: "nix", Dispatched: false}},
+		a.Steps[0].Runners)
+	assert.True(t, a.Steps[1].Excluded)
+
+	b := plan.Targets[1]
+	assert.EqualValues(t, "b::build", b.ID)
+	assert.Empty(t, b.Steps)
+	assert.Contains(t, plan.Format(), "'b::build'")
+
+	// With the tag the step is included.
+	plan, err = NewPlan(prios, newTestFactory(t, "test", nil), true, WithTags("ci"))
+	require.NoError(t, err)
+	assert.False(t, plan.Targets[0].Steps[1].Excluded)
+}
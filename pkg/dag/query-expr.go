@@ -0,0 +1,324 @@
+package dag
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/sdsc-ordes/quitsh/pkg/common/set"
+	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+// ChangedFunc returns all targets which changed since revision `rev`.
+type ChangedFunc func(rev string) ([]target.ID, error)
+
+type queryParser struct {
+	nodes   TargetNodeMap
+	changed ChangedFunc
+
+	tokens []string
+	pos    int
+}
+
+const queryDelimiters = "()&|,"
+
+// Query evaluates the query expression `expr` over all target nodes `nodes`
+// and returns the matching target ids sorted.
+//
+// The expression is built from:
+//   - `<glob>`: all targets whose id matches the glob (e.g. `*::test`).
+//   - `<id>[<params>]`: all matrix targets with matching parameters
+//     (e.g. `comp::build[a=1]` or an expanded id `comp::build[a=1,b=2]`).
+//   - `deps(<expr>)`: all transitive dependencies of the targets in `<expr>`.
+//   - `rdeps(<expr>)`: all transitive dependents of the targets in `<expr>`.
+//   - `stage(<stage>)`: all targets in stage `<stage>`.
+//   - `component(<glob>)`: all targets of components whose name matches the glob.
+//   - `changed(<rev>)`: all targets changed since revision `<rev>` (over `changed`).
+//   - `<expr> & <expr>`: the intersection, `<expr> | <expr>`: the union
+//     and parentheses for grouping (`&` binds stronger than `|`).
+func Query(nodes TargetNodeMap, expr string, changed ChangedFunc) ([]target.ID, error) {
+	p := queryParser{nodes: nodes, changed: changed, tokens: tokenizeQuery(expr)}
+
+	res, err := p.parseUnion()
+	if err == nil && p.pos != len(p.tokens) {
+		err = errors.New("unexpected token '%v'", p.tokens[p.pos])
+	}
+
+	if err != nil {
+		return nil, errors.AddContext(err, "could not evaluate query '%v'", expr)
+	}
+
+	return slices.Sorted(res.Keys()), nil
+}
+
+func tokenizeQuery(expr string) (tokens []string) {
+	var word strings.Builder
+	flush := func() {
+		if word.Len() != 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	// Parameter lists `[a=1,b=2]` of matrix targets are kept together.
+	brackets := 0
+
+	for _, r := range expr {
+		switch {
+		case r == '[':
+			brackets++
+			word.WriteRune(r)
+		case r == ']' && brackets > 0:
+			brackets--
+			word.WriteRune(r)
+		case brackets > 0:
+			if !unicode.IsSpace(r) {
+				word.WriteRune(r)
+			}
+		case unicode.IsSpace(r):
+			flush()
+		case strings.ContainsRune(queryDelimiters, r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+func (p *queryParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) expect(token string) error {
+	if p.peek() != token {
+		if p.pos >= len(p.tokens) {
+			return errors.New("expected '%v' but reached the end", token)
+		}
+
+		return errors.New("expected '%v' but got '%v'", token, p.peek())
+	}
+	p.pos++
+
+	return nil
+}
+
+// parseUnion parses `term ('|' term)*`.
+func (p *queryParser) parseUnion() (TargetSelection, error) {
+	res, err := p.parseIntersection()
+	if err != nil {
+		return res, err
+	}
+
+	for p.peek() == "|" {
+		p.pos++
+
+		other, e := p.parseIntersection()
+		if e != nil {
+			return res, e
+		}
+
+		for id := range other.Keys() {
+			res.Insert(id)
+		}
+	}
+
+	return res, nil
+}
+
+// parseIntersection parses `factor ('&' factor)*`.
+func (p *queryParser) parseIntersection() (TargetSelection, error) {
+	res, err := p.parseFactor()
+	if err != nil {
+		return res, err
+	}
+
+	for p.peek() == "&" {
+		p.pos++
+
+		other, e := p.parseFactor()
+		if e != nil {
+			return res, e
+		}
+
+		for _, id := range slices.Collect(res.Keys()) {
+			if !other.Exists(id) {
+				res.Remove(id)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// parseFactor parses `'(' expr ')' | func '(' arg ')' | glob`.
+func (p *queryParser) parseFactor() (TargetSelection, error) {
+	token := p.peek()
+
+	switch {
+	case token == "":
+		return TargetSelection{}, errors.New("unexpected end of query")
+	case token == "(":
+		p.pos++
+
+		res, err := p.parseUnion()
+		if err != nil {
+			return res, err
+		}
+
+		return res, p.expect(")")
+	case strings.Contains(queryDelimiters, token):
+		return TargetSelection{}, errors.New("unexpected token '%v'", token)
+	}
+
+	p.pos++
+	if p.peek() != "(" {
+		return p.matchTargets(token)
+	}
+	p.pos++
+
+	var res TargetSelection
+	var err error
+
+	switch token {
+	case "deps", "rdeps":
+		res, err = p.parseUnion()
+		if err != nil {
+			return res, err
+		}
+
+		dir := backwardDir
+		if token == "rdeps" {
+			dir = forwardDir
+		}
+
+		res = p.related(&res, dir)
+	case "stage", "component", "changed":
+		arg := p.peek()
+		if arg == "" || strings.Contains(queryDelimiters, arg) {
+			return res, errors.New("function '%v' expects an argument", token)
+		}
+		p.pos++
+
+		res, err = p.evalFunc(token, arg)
+		if err != nil {
+			return res, err
+		}
+	default:
+		return res, errors.New(
+			"unknown function '%v', use 'deps', 'rdeps', 'stage', 'component' or 'changed'",
+			token)
+	}
+
+	return res, p.expect(")")
+}
+
+func (p *queryParser) evalFunc(name string, arg string) (res TargetSelection, err error) {
+	res = set.NewUnordered[target.ID]()
+
+	switch name {
+	case "stage":
+		for id, n := range p.nodes {
+			if n.Target.Stage == stage.Stage(arg) {
+				res.Insert(id)
+			}
+		}
+	case "component":
+		if !doublestar.ValidatePattern(arg) {
+			return res, errors.New("invalid component pattern '%v'", arg)
+		}
+
+		for id, n := range p.nodes {
+			if m, _ := doublestar.Match(arg, n.Comp.Name()); m {
+				res.Insert(id)
+			}
+		}
+	case "changed":
+		if p.changed == nil {
+			return res, errors.New("function 'changed' is not supported")
+		}
+
+		ids, e := p.changed(arg)
+		if e != nil {
+			return res, e
+		}
+
+		for _, id := range ids {
+			if _, exists := p.nodes[id]; exists {
+				res.Insert(id)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// matchTargets returns all targets matching the glob `pattern`.
+// An existing target id (e.g. an expanded matrix target `comp::build[a=1,b=2]`)
+// matches exactly and a matrix target id with parameters matches all
+// variants with these parameters (e.g. `comp::build[a=1]`).
+// A pattern without wildcards must match an existing target.
+func (p *queryParser) matchTargets(pattern string) (TargetSelection, error) {
+	res := set.NewUnordered[target.ID]()
+
+	if _, exists := p.nodes[target.ID(pattern)]; exists {
+		res.Insert(target.ID(pattern))
+
+		return res, nil
+	}
+
+	id := target.ID(pattern)
+	if base, params := id.SplitParameters(); params != nil {
+		for other, n := range p.nodes {
+			if b, _ := other.SplitParameters(); b == base && n.Target.Parameters.Matches(params) {
+				res.Insert(other)
+			}
+		}
+
+		if res.Len() == 0 {
+			return res, errors.New("matrix target '%v' does not exist", pattern)
+		}
+
+		return res, nil
+	}
+
+	if !doublestar.ValidatePattern(pattern) {
+		return res, errors.New("invalid target pattern '%v'", pattern)
+	}
+
+	for id := range p.nodes {
+		if m, _ := doublestar.Match(pattern, id.String()); m {
+			res.Insert(id)
+		}
+	}
+
+	if res.Len() == 0 && !strings.ContainsAny(pattern, "*?[{") {
+		return res, errors.New("target id '%v' does not exist", pattern)
+	}
+
+	return res, nil
+}
+
+// related returns all transitive dependencies (or dependents)
+// of all targets in `sel`.
+func (p *queryParser) related(sel *TargetSelection, dir traverseDirection) TargetSelection {
+	start := make([]*TargetNode, 0, sel.Len())
+	for id := range sel.Keys() {
+		start = append(start, p.nodes[id])
+	}
+
+	res := set.NewUnordered[target.ID]()
+	collectRelated(&res, start, true, dir)
+
+	return res
+}
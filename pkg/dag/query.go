@@ -0,0 +1,124 @@
+package dag
+
+import (
+	"slices"
+
+	"github.com/sdsc-ordes/quitsh/pkg/common/set"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+)
+
+// Dependencies returns the targets in `nodes` which target `id` depends on,
+// sorted by id. If `transitive` is set, all transitive dependencies are returned,
+// otherwise only the direct ones.
+func Dependencies(nodes TargetNodeMap, id target.ID, transitive bool) ([]target.ID, error) {
+	return relatedTargets(nodes, id, transitive, backwardDir)
+}
+
+// Dependents returns the targets in `nodes` which depend on target `id`,
+// sorted by id. If `transitive` is set, all transitive dependents are returned,
+// otherwise only the direct ones.
+func Dependents(nodes TargetNodeMap, id target.ID, transitive bool) ([]target.ID, error) {
+	return relatedTargets(nodes, id, transitive, forwardDir)
+}
+
+// PathsBetween returns all dependency paths in `nodes` from target `from`
+// to target `to` (both included), where each target on a path depends on the next one.
+// The paths are sorted.
+func PathsBetween(nodes TargetNodeMap, from target.ID, to target.ID) ([][]target.ID, error) {
+	start, err := findNode(nodes, from)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = findNode(nodes, to); err != nil {
+		return nil, err
+	}
+
+	var paths [][]target.ID
+	var path []target.ID
+
+	// The graph is acyclic, so a plain DFS terminates.
+	var visit func(n *TargetNode)
+	visit = func(n *TargetNode) {
+		path = append(path, n.Target.ID)
+		defer func() { path = path[:len(path)-1] }()
+
+		if n.Target.ID == to {
+			paths = append(paths, slices.Clone(path))
+
+			return
+		}
+
+		for _, b := range n.Backward {
+			visit(b)
+		}
+	}
+	visit(start)
+
+	slices.SortFunc(paths, slices.Compare)
+
+	return slices.CompactFunc(paths, slices.Equal), nil
+}
+
+func relatedTargets(
+	nodes TargetNodeMap,
+	id target.ID,
+	transitive bool,
+	dir traverseDirection,
+) ([]target.ID, error) {
+	n, err := findNode(nodes, id)
+	if err != nil {
+		return nil, err
+	}
+
+	related := set.NewUnordered[target.ID]()
+	collectRelated(&related, []*TargetNode{n}, transitive, dir)
+
+	return slices.Sorted(related.Keys()), nil
+}
+
+// collectRelated inserts all targets related to `start` in direction `dir` (direct or
+// transitive) into `result`. The start nodes themselves are only inserted if
+// reached from another one.
+func collectRelated(
+	result *TargetSelection,
+	start []*TargetNode,
+	transitive bool,
+	dir traverseDirection,
+) {
+	next := func(n *TargetNode) []*TargetNode {
+		if dir == forwardDir {
+			return n.Forward
+		}
+
+		return n.Backward
+	}
+
+	if !transitive {
+		for _, n := range start {
+			for _, r := range next(n) {
+				result.Insert(r.Target.ID)
+			}
+		}
+
+		return
+	}
+
+	var startNext []*TargetNode
+	for _, n := range start {
+		startNext = append(startNext, next(n)...)
+	}
+
+	visitNodesBFS(startNext, func(n *TargetNode) bool {
+		return !result.Insert(n.Target.ID)
+	}, dir)
+}
+
+func findNode(nodes TargetNodeMap, id target.ID) (*TargetNode, error) {
+	n, exists := nodes[id]
+	if !exists {
+		return nil, errors.New("target id '%v' does not exist", id)
+	}
+
+	return n, nil
+}
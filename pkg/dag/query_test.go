@@ -0,0 +1,143 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQueries(t *testing.T) {
+	t.Parallel()
+
+	nodes, _, e := DefineExecutionOrder(generateChainComps(t), rootDir)
+	require.NoError(t, e)
+
+	ids, e := Dependents(nodes, "1::build", false)
+	require.NoError(t, e)
+	assert.Equal(t, []target.ID{"2::build"}, ids)
+
+	ids, e = Dependents(nodes, "1::build", true)
+	require.NoError(t, e)
+	assert.Equal(t, []target.ID{"2::build", "3::build"}, ids)
+
+	ids, e = Dependencies(nodes, "3::build", true)
+	require.NoError(t, e)
+	assert.Equal(t, []target.ID{"1::build", "2::build"}, ids)
+
+	ids, e = Dependencies(nodes, "4::build", true)
+	require.NoError(t, e)
+	assert.Empty(t, ids)
+
+	_, e = Dependencies(nodes, "5::build", true)
+	require.ErrorContains(t, e, "does not exist")
+
+	paths, e := PathsBetween(nodes, "3::build", "1::build")
+	require.NoError(t, e)
+	assert.Equal(t, [][]target.ID{{"3::build", "2::build", "1::build"}}, paths)
+
+	paths, e = PathsBetween(nodes, "1::build", "3::build")
+	require.NoError(t, e)
+	assert.Empty(t, paths)
+}
+
+func TestPathsBetweenMultiple(t *testing.T) {
+	t.Parallel()
+
+	// 1 <- 2 <- 3 and 1 <- 3.
+	comps, _ := generate3Comps(t)
+	nodes, _, e := DefineExecutionOrder(comps, rootDir)
+	require.NoError(t, e)
+
+	paths, e := PathsBetween(nodes, "3::build3", "1::build1")
+	require.NoError(t, e)
+	assert.Equal(t,
+		[][]target.ID{
+			{"3::build3", "1::build1"},
+			{"3::build3", "2::build2", "1::build1"},
+		}, paths)
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	nodes, _, e := DefineExecutionOrder(generateChainComps(t), rootDir)
+	require.NoError(t, e)
+
+	changed := func(rev string) ([]target.ID, error) {
+		assert.Equal(t, "origin/main", rev)
+
+		return []target.ID{"2::build", "3::build"}, nil
+	}
+
+	tests := []struct {
+		expr     string
+		expected []target.ID
+	}{
+		{"1::build", []target.ID{"1::build"}},
+		{"*::build", []target.ID{"1::build", "2::build", "3::build", "4::build"}},
+		{"rdeps(1::build)", []target.ID{"2::build", "3::build"}},
+		{"deps(3::build)", []target.ID{"1::build", "2::build"}},
+		{"deps(3::build | 2::build)", []target.ID{"1::build", "2::build"}},
+		{"component(4)", []target.ID{"4::build"}},
+		{"stage(build) & changed(origin/main)", []target.ID{"2::build", "3::build"}},
+		{"rdeps(1::build) & changed(origin/main) | 4::build",
+			[]target.ID{"2::build", "3::build", "4::build"}},
+		{"rdeps(1::build) & (changed(origin/main) | 4::build)",
+			[]target.ID{"2::build", "3::build"}},
+		{"stage(test)", []target.ID{}},
+	}
+
+	for _, test := range tests {
+		ids, err := Query(nodes, test.expr, changed)
+		require.NoError(t, err, test.expr)
+		assert.ElementsMatch(t, test.expected, ids, test.expr)
+	}
+
+	for _, expr := range []string{
+		"5::build",
+		"deps(1::build",
+		"unknown(1::build)",
+		"1::build 2::build",
+		"stage()",
+		"1::build &",
+	} {
+		_, err := Query(nodes, expr, changed)
+		require.Error(t, err, expr)
+	}
+
+	_, err := Query(nodes, "changed(HEAD)", nil)
+	require.ErrorContains(t, err, "not supported")
+}
+
+func TestQueryMatrix(t *testing.T) {
+	t.Parallel()
+
+	nodes, _, e := DefineExecutionOrder(generateMatrixComps(t), rootDir)
+	require.NoError(t, e)
+
+	tests := []struct {
+		expr     string
+		expected []target.ID
+	}{
+		{"1::build[buildType=debug,go=1.25]", []target.ID{"1::build[buildType=debug,go=1.25]"}},
+		{"1::build[go=1.25] & 1::build[buildType=release]",
+			[]target.ID{"1::build[buildType=release,go=1.25]"}},
+		{"rdeps(1::build[buildType=debug, go=1.25])", []target.ID{"1::test-all", "1::test-one"}},
+		{"deps(1::test-release)",
+			[]target.ID{"1::build[buildType=release,go=1.24]", "1::build[buildType=release,go=1.25]"}},
+	}
+
+	for _, test := range tests {
+		ids, err := Query(nodes, test.expr, nil)
+		require.NoError(t, err, test.expr)
+		assert.ElementsMatch(t, test.expected, ids, test.expr)
+	}
+
+	_, err := Query(nodes, "1::build[buildType=none]", nil)
+	require.ErrorContains(t, err, "does not exist")
+}
@@ -0,0 +1,246 @@
+package dag
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+)
+
+// ReportFormat is the format of an execution report (see [WriteReport]).
+type ReportFormat string
+
+const (
+	ReportFormatJSON  ReportFormat = "json"
+	ReportFormatJUnit ReportFormat = "junit"
+)
+
+type (
+	// Report is the machine-readable execution report.
+	Report struct {
+		Runners []ReportRunner `json:"runners"`
+	}
+
+	// ReportRunner is the result of one runner in the [Report].
+	ReportRunner struct {
+		Component string            `json:"component"`
+		TargetID  target.ID         `json:"target"`
+		StepIdx   step.Index        `json:"step"`
+		RunnerID  runner.RegisterID `json:"runner"`
+		Toolchain string            `json:"toolchain"`
+
+		Status   string `json:"status"`
+		Error    string `json:"error,omitempty"`
+		Attempts int    `json:"attempts,omitempty"`
+
+		// The start time (zero if not run) and the duration in seconds.
+		Start    time.Time `json:"start"`
+		Duration float64   `json:"duration"`
+
+		status ExecStatus
+	}
+
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Skipped  int              `xml:"skipped,attr"`
+		Time     float64          `xml:"time,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		Skipped   int             `xml:"skipped,attr"`
+		Time      float64         `xml:"time,attr"`
+		Timestamp string          `xml:"timestamp,attr,omitempty"`
+		Cases     []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Time      float64       `xml:"time,attr"`
+		Failure   *junitMessage `xml:"failure,omitempty"`
+		Skipped   *junitMessage `xml:"skipped,omitempty"`
+	}
+
+	junitMessage struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// NewReport creates the report over all runner statuses `statuses`
+// sorted by target id and step.
+func NewReport(statuses RunnerStatuses) (r Report) {
+	r.Runners = []ReportRunner{}
+
+	for _, s := range statuses {
+		rr := ReportRunner{
+			Component: s.CompName,
+			TargetID:  s.TargetID,
+			StepIdx:   s.StepIdx,
+			RunnerID:  s.RunnerID,
+			Toolchain: s.Toolchain,
+			Status:    s.Status.String(),
+			Attempts:  s.Attempts,
+			Start:     s.Start,
+			Duration:  s.Duration.Seconds(),
+			status:    s.Status,
+		}
+
+		if s.Error != nil {
+			rr.Error = s.Error.Error()
+		}
+
+		r.Runners = append(r.Runners, rr)
+	}
+
+	r.sort()
+
+	return r
+}
+
+// sort sorts the runners by target id and step.
+func (r *Report) sort() {
+	slices.SortStableFunc(r.Runners, func(a, b ReportRunner) int {
+		return cmp.Or(
+			cmp.Compare(a.TargetID, b.TargetID),
+			cmp.Compare(a.StepIdx, b.StepIdx))
+	})
+}
+
+// ReportFormatFromPath returns the report format given by the extension of `path`:
+// `.xml` for JUnit XML and `.json` for JSON.
+func ReportFormatFromPath(path string) (ReportFormat, error) {
+	switch filepath.Ext(path) {
+	case ".xml":
+		return ReportFormatJUnit, nil
+	case ".json":
+		return ReportFormatJSON, nil
+	default:
+		return "", errors.New(
+			"cannot determine report format from file '%v', use '.json' or '.xml'", path)
+	}
+}
+
+// WriteReport writes the report over all runner statuses `statuses`
+// in format `format` to `w`.
+func WriteReport(w io.Writer, statuses RunnerStatuses, format ReportFormat) error {
+	r := NewReport(statuses)
+
+	switch format {
+	case ReportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(r)
+	case ReportFormatJUnit:
+		return r.writeJUnit(w)
+	default:
+		return errors.New("unknown report format '%v'", format)
+	}
+}
+
+// WriteReportFile writes the report over all runners of all `targets` to
+// the file `path` in the format given by its extension (see [ReportFormatFromPath]).
+func WriteReportFile(path string, targets TargetNodeMap) error {
+	format, err := ReportFormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	var statuses RunnerStatuses
+	for _, n := range targets {
+		statuses = append(statuses, n.Execution.Runners...)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), fs.DefaultPermissionsDir)
+	if err != nil {
+		return errors.AddContext(err, "could not create directory for report '%v'", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.AddContext(err, "could not create report '%v'", path)
+	}
+	defer f.Close()
+
+	return errors.AddContext(WriteReport(f, statuses, format),
+		"could not write report '%v'", path)
+}
+
+// writeJUnit writes the report as JUnit XML with one test suite per target
+// and one test case per runner.
+func (r *Report) writeJUnit(w io.Writer) error {
+	var suites junitTestSuites
+
+	for _, rr := range r.Runners {
+		if len(suites.Suites) == 0 ||
+			suites.Suites[len(suites.Suites)-1].Name != rr.TargetID.String() {
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: rr.TargetID.String()})
+		}
+		suite := &suites.Suites[len(suites.Suites)-1]
+
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("step %v: %v", rr.StepIdx, rr.RunnerID),
+			ClassName: rr.Component + "." + rr.TargetID.String(),
+			Time:      rr.Duration,
+		}
+
+		switch rr.status {
+		case ExecStatusFailed:
+			tc.Failure = &junitMessage{Message: "runner failed", Text: rr.Error}
+			suite.Failures++
+		case ExecStatusNotRun, ExecStatusCancelled:
+			tc.Skipped = &junitMessage{Message: "runner " + rr.Status}
+			suite.Skipped++
+		}
+
+		if suite.Timestamp == "" && !rr.Start.IsZero() {
+			suite.Timestamp = rr.Start.Format(time.RFC3339)
+		}
+
+		suite.Tests++
+		suite.Time += rr.Duration
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	for i := range suites.Suites {
+		suites.Tests += suites.Suites[i].Tests
+		suites.Failures += suites.Suites[i].Failures
+		suites.Skipped += suites.Suites[i].Skipped
+		suites.Time += suites.Suites[i].Time
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	err = enc.Encode(suites)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
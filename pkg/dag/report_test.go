@@ -0,0 +1,92 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatuses() RunnerStatuses {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return RunnerStatuses{
+		{
+			Status: ExecStatusFailed, Error: errors.New("boom"),
+			CompName: "b", TargetID: "b::build", StepIdx: 0, RunnerID: "go-build",
+			Toolchain: "go", Start: start, Duration: 2 * time.Second,
+		},
+		{
+			Status:   ExecStatusSuccess,
+			CompName: "a", TargetID: "a::build", StepIdx: 1, RunnerID: "go-test",
+			Toolchain: "go", Start: start, Duration: time.Second,
+		},
+		{
+			Status:   ExecStatusNotRun,
+			CompName: "a", TargetID: "a::build", StepIdx: 0, RunnerID: "go-build",
+			Toolchain: "go",
+		},
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf, testStatuses(), ReportFormatJSON))
+
+	var r Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+	require.Len(t, r.Runners, 3)
+
+	assert.EqualValues(t, "a::build", r.Runners[0].TargetID)
+	assert.EqualValues(t, 0, r.Runners[0].StepIdx)
+	assert.Equal(t, "not-run", r.Runners[0].Status)
+
+	assert.Equal(t, "failed", r.Runners[2].Status)
+	assert.Equal(t, "boom", r.Runners[2].Error)
+	assert.InDelta(t, 2.0, r.Runners[2].Duration, 1e-9)
+	assert.Equal(t, "go", r.Runners[2].Toolchain)
+}
+
+func TestReportJUnit(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf, testStatuses(), ReportFormatJUnit))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(t, 3, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 1, suites.Skipped)
+
+	require.Len(t, suites.Suites, 2)
+	assert.Equal(t, "a::build", suites.Suites[0].Name)
+	assert.Equal(t, "b::build", suites.Suites[1].Name)
+	require.NotNil(t, suites.Suites[1].Cases[0].Failure)
+	assert.Equal(t, "boom", suites.Suites[1].Cases[0].Failure.Text)
+}
+
+func TestReportFormatFromPath(t *testing.T) {
+	t.Parallel()
+
+	f, err := ReportFormatFromPath("out/report.xml")
+	require.NoError(t, err)
+	assert.Equal(t, ReportFormatJUnit, f)
+
+	f, err = ReportFormatFromPath("report.json")
+	require.NoError(t, err)
+	assert.Equal(t, ReportFormatJSON, f)
+
+	_, err = ReportFormatFromPath("report.txt")
+	require.Error(t, err)
+}
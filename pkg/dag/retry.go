@@ -0,0 +1,99 @@
+package dag
+
+import (
+	"context"
+	stderr "errors"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/exec"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+// runAttempts runs `run` for the runner with status `status` of step `step` on node `node`
+// until it succeeds or the retry policy of the step is exhausted.
+// Each attempt runs on its own runner context (see [runState.runnerContext])
+// and holds a job slot only while it runs, not during the backoff.
+// Timeouts and cancellations are never retried.
+// Returns the error and the context of the last attempt (see [runState.setResult]).
+func (s *runState) runAttempts(
+	node *TargetNode,
+	step *step.Config,
+	status *RunnerStatus,
+	run func(ctx context.Context) error,
+) (context.Context, error) {
+	maxAttempts := step.Retry.MaxAttempts()
+
+	defer func() {
+		if !status.Start.IsZero() {
+			status.Duration = time.Since(status.Start)
+		}
+	}()
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			log.Info("Retrying runner.",
+				"target", node.Target.ID,
+				"step", step.Index,
+				"runner", status.RunnerID,
+				"attempt", attempt, "max", maxAttempts)
+		}
+
+		ctx, err := s.runAttempt(node, step, status, attempt, run)
+		if ctx == nil {
+			return s.ctx, err
+		}
+
+		if err == nil || attempt >= maxAttempts ||
+			s.isCancelled() || stderr.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ctx, err
+		}
+
+		if !step.Retry.Matches(exec.GetExitCode(err)) {
+			log.Debug("Runner failure is not retried: exit code does not match.",
+				"target", node.Target.ID, "step", step.Index, "exitCodes", step.Retry.OnExitCodes)
+
+			return ctx, err
+		}
+
+		log.Warn("Runner attempt failed.",
+			"target", node.Target.ID,
+			"step", step.Index,
+			"runner", status.RunnerID,
+			"attempt", attempt, "max", maxAttempts,
+			"backoff", step.Retry.Backoff,
+			"error", err)
+
+		select {
+		case <-time.After(step.Retry.Backoff):
+		case <-s.ctx.Done():
+			return ctx, err
+		}
+	}
+}
+
+// runAttempt runs attempt `attempt` of `run` while holding a job slot.
+// Returns a `nil` context if no slot was acquired.
+func (s *runState) runAttempt(
+	node *TargetNode,
+	step *step.Config,
+	status *RunnerStatus,
+	attempt int,
+	run func(ctx context.Context) error,
+) (context.Context, error) {
+	err := s.slots.acquire(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.slots.release()
+
+	if attempt == 1 {
+		status.Start = time.Now()
+	}
+	status.Attempts = attempt
+
+	ctx, cancel := s.runnerContext(node, step)
+	defer cancel()
+
+	return ctx, run(ctx)
+}
@@ -0,0 +1,129 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/exec"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	node := nodes["a::build"]
+
+	s := newRunState(&execOption{ctx: context.Background()})
+	defer s.cancel()
+
+	// Passes on the second attempt.
+	st := &step.Config{Retry: step.Retry{Attempts: 3, Backoff: time.Millisecond}}
+	calls := 0
+	var status RunnerStatus
+	ctx, err := s.runAttempts(node, st, &status, func(context.Context) error {
+		calls++
+		if calls == 1 {
+			return errors.New("flaky")
+		}
+
+		return nil
+	})
+	s.setResult(ctx, &status, err)
+	assert.EqualValues(t, ExecStatusSuccess, status.Status)
+	assert.Equal(t, 2, status.Attempts)
+
+	// Gives up after all attempts.
+	calls = 0
+	status = RunnerStatus{}
+	_, err = s.runAttempts(node, st, &status, func(context.Context) error {
+		calls++
+
+		return errors.New("broken")
+	})
+	require.Error(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, status.Attempts)
+}
+
+func TestRetryExitCodes(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	node := nodes["a::build"]
+
+	s := newRunState(&execOption{ctx: context.Background()})
+	defer s.cancel()
+
+	st := &step.Config{Retry: step.Retry{Attempts: 3, OnExitCodes: []int{2}}}
+	run := func(code int) (int, error) {
+		calls := 0
+		_, err := s.runAttempts(node, st, &RunnerStatus{}, func(context.Context) error {
+			calls++
+
+			return exec.NewCmdCtxBuilder().Build().Check("sh", "-c", "exit $0", strconv.Itoa(code))
+		})
+
+		return calls, err
+	}
+
+	calls, err := run(2)
+	require.Error(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls, err = run(1)
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryBackoffReleasesSlot(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	node := nodes["a::build"]
+
+	s := newRunState(&execOption{ctx: context.Background(), jobs: 1})
+	defer s.cancel()
+
+	st := &step.Config{Retry: step.Retry{Attempts: 2, Backoff: time.Second}}
+	failed := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		calls := 0
+		_, e := s.runAttempts(node, st, &RunnerStatus{}, func(context.Context) error {
+			calls++
+			if calls == 1 {
+				close(failed)
+
+				return errors.New("flaky")
+			}
+
+			return nil
+		})
+		done <- e
+	}()
+
+	// The only job slot is free during the backoff.
+	<-failed
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	require.NoError(t, s.slots.acquire(ctx))
+	s.slots.release()
+
+	require.NoError(t, <-done)
+}
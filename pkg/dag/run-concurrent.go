@@ -1,7 +1,9 @@
 package dag
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 
 	taskflow "github.com/noneback/go-taskflow"
 	"github.com/sdsc-ordes/quitsh/pkg/component/step"
@@ -9,31 +11,61 @@ import (
 	"github.com/sdsc-ordes/quitsh/pkg/config"
 	"github.com/sdsc-ordes/quitsh/pkg/errors"
 	"github.com/sdsc-ordes/quitsh/pkg/log"
-	"github.com/sdsc-ordes/quitsh/pkg/runner"
 	"github.com/sdsc-ordes/quitsh/pkg/runner/factory"
 	"github.com/sdsc-ordes/quitsh/pkg/toolchain"
 )
 
 const MaxCoroutineConcurrency = 10000
 
+// jobSlots limits the number of runners executing at the same time.
+type jobSlots chan struct{}
+
+func newJobSlots(jobs int) jobSlots {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	return make(jobSlots, jobs)
+}
+
+// acquire blocks until a slot is free or the context `ctx` is cancelled.
+func (j jobSlots) acquire(ctx context.Context) error {
+	select {
+	case j <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot.
+func (j jobSlots) release() {
+	<-j
+}
+
 // executeConcurrent executes the DAG concurrent.
 func executeConcurrent(
+	state *runState,
 	targetNodes TargetNodeMap,
 	runnerFactory factory.IFactory,
 	toolchainDispatcher toolchain.IDispatcher,
 	config config.IConfig,
 	rootDir string,
-	opts ...ExecuteOption,
+	opt *execOption,
 ) error {
-	opt := execOption{}
-	if e := opt.Apply(opts...); e != nil {
-		return e
-	}
-
 	executor := taskflow.NewExecutor(MaxCoroutineConcurrency)
 	tf := taskflow.NewTaskFlow("DAG")
 
-	var buildError error
+	log.Info("Execute targets concurrently.", "jobs", cap(state.slots))
+
+	locks := newTargetLocks(targetNodes)
+
+	// Instantiate all runners upfront, such that no task flow
+	// is executed with missing runners.
+	allRunners, err := createAllRunners(targetNodes, runnerFactory, opt)
+	if err != nil {
+		return errors.AddContext(err, "failed to assemble all runners")
+	}
 
 	tasks := make(map[target.ID]*taskflow.Task, 0)
 	for _, node := range targetNodes {
@@ -46,25 +78,52 @@ func executeConcurrent(
 						fmt.Sprintf("%v::step-%v", node.Target.ID, stepIdx),
 
 						func(sf *taskflow.Subflow) {
-							e := addRunnerTasks(
+							addRunnerTasks(
+								state,
 								config,
 								toolchainDispatcher,
-								runnerFactory,
 								rootDir,
 								sf, node,
 								&node.Target.Steps[stepIdx],
-								stepIdx,
-								&opt)
-
-							buildError = errors.Combine(buildError, e)
+								allRunners[node.Target.ID][stepIdx])
 						},
 					)
 					stepTasks = append(stepTasks, stepTask)
 				}
 
 				// Link all steps together.
-				for i := 1; i < len(stepTasks); i++ {
-					stepTasks[i].Succeed(stepTasks[i-1])
+				for i, preds := range stepPredecessors(node.Target) {
+					for _, p := range preds {
+						stepTasks[i].Succeed(stepTasks[p])
+					}
+				}
+
+				// Validate the outputs after all steps.
+				outTask := sf.NewTask(
+					fmt.Sprintf("%v::outputs", node.Target.ID),
+					func() { state.finishTarget(node) })
+				for _, t := range stepTasks {
+					outTask.Succeed(t)
+				}
+
+				// Start the target (after acquiring its locks) before all steps.
+				startTask := sf.NewTask(
+					fmt.Sprintf("%v::start", node.Target.ID),
+					func() {
+						locks.lock(node.Target.ID, node.Target.Locks)
+						state.startTarget(node)
+					})
+				for _, t := range stepTasks {
+					t.Succeed(startTask)
+				}
+				outTask.Succeed(startTask)
+
+				// Hold the locks of the target over all steps.
+				if len(node.Target.Locks) != 0 {
+					unlockTask := sf.NewTask(
+						fmt.Sprintf("%v::unlock", node.Target.ID),
+						func() { locks.unlock(node.Target.ID, node.Target.Locks) })
+					unlockTask.Succeed(outTask)
 				}
 			})
 
@@ -85,11 +144,13 @@ func executeConcurrent(
 
 			task.Succeed(t)
 		}
-	}
 
-	if buildError != nil {
-		return errors.AddContext(buildError,
-			"Build errors while constructing the task flowk.")
+		// Weak ordering only applies if both targets are executed.
+		for _, a := range t.After {
+			if t, ok := tasks[a.Target.ID]; ok {
+				task.Succeed(t)
+			}
+		}
 	}
 
 	executor.Run(tf).Wait()
@@ -100,90 +161,97 @@ func executeConcurrent(
 	}
 	summary.Log()
 
-	return summary.allErrors
+	return state.checkCancelled(summary.allErrors)
 }
 
-//nolint:funlen
-func addRunnerTasks(
-	config config.IConfig,
-	toolchainDispatcher toolchain.IDispatcher,
+// createAllRunners instantiates the runners of all steps of all targets in `targetNodes`
+// and allocates their status (in step order, before any task runs).
+// The runners of steps excluded by tags are `nil`.
+func createAllRunners(
+	targetNodes TargetNodeMap,
 	runnerFactory factory.IFactory,
-	rootDir string,
-	sf *taskflow.Subflow,
-	node *TargetNode,
-	step *step.Config,
-	stepIdx int,
 	opt *execOption,
-) error {
-	var runners []factory.RunnerInstance
-	{
-		var e error
-		if !step.Include.TagExpr.Matches(opt.Tags) {
-			log.Debugf(
-				"Target: '%v' -> step idx: '%v' excluded: expr '%v' "+
-					"does not match for tags '%q'",
-				node.Target.ID, stepIdx,
-				step.Include.TagExpr.String(), opt.Tags)
-
-			return nil
-		}
+) (all map[target.ID][][]RunnerData, err error) {
+	all = make(map[target.ID][][]RunnerData, len(targetNodes))
 
-		if step.RunnerID != "" {
-			runners, e = runnerFactory.CreateByID(
-				step.RunnerID, step.Toolchain, step.ConfigRaw)
-		} else if step.Runner != "" {
-			runners, e = runnerFactory.CreateByKey(
-				runner.NewRegisterKey(node.Target.Stage, step.Runner),
-				step.Toolchain,
-				step.ConfigRaw,
-			)
-		}
+	for _, node := range targetNodes {
+		steps := make([][]RunnerData, len(node.Target.Steps))
+
+		for stepIdx := range node.Target.Steps {
+			step := &node.Target.Steps[stepIdx]
+
+			if !step.Include.TagExpr.Matches(opt.Tags) {
+				log.Debugf(
+					"Target: '%v' -> step idx: '%v' excluded: expr '%v' "+
+						"does not match for tags '%q'",
+					node.Target.ID, stepIdx,
+					step.Include.TagExpr.String(), opt.Tags)
+
+				continue
+			}
+
+			runners, e := createRunners(runnerFactory, node, step)
+			if e != nil {
+				err = errors.Combine(err, e)
+
+				continue
+			}
 
-		if e != nil {
-			return errors.AddContext(e,
-				"could not instantiate runner for target '%v'", node.Target.ID)
+			for runnerIdx, r := range runners {
+				steps[stepIdx] = append(steps[stepIdx],
+					RunnerData{
+						node:      node,
+						comp:      node.Comp,
+						status:    node.Execution.AddRunnerStatus(),
+						targetID:  node.Target.ID,
+						step:      step,
+						runnerIdx: runnerIdx,
+						inst:      r,
+					})
+			}
 		}
+
+		all[node.Target.ID] = steps
 	}
 
+	return all, err
+}
+
+// addRunnerTasks adds the tasks for all runners `runners` of step `step` on node `node`
+// to the subflow `sf`.
+func addRunnerTasks(
+	state *runState,
+	config config.IConfig,
+	toolchainDispatcher toolchain.IDispatcher,
+	rootDir string,
+	sf *taskflow.Subflow,
+	node *TargetNode,
+	step *step.Config,
+	runners []RunnerData,
+) {
 	newTask := func(
 		name string,
-		logger log.ILog,
 		runner factory.RunnerInstance,
 		status *RunnerStatus,
 		runnerIdx int) func() {
 		return func() {
-			*status = RunnerStatus{
-				ExecStatusNotRun,
-				nil,
-				node.Comp.Name(),
-				node.Target.ID,
-				step.Index,
-				runner.RunnerID,
+			init := RunnerStatus{
+				Status:    ExecStatusNotRun,
+				CompName:  node.Comp.Name(),
+				TargetID:  node.Target.ID,
+				StepIdx:   step.Index,
+				RunnerID:  runner.RunnerID,
+				Toolchain: runner.Toolchain,
+
+				Dispatched: isDispatched(runner.Toolchain, toolchainDispatcher),
 			}
 
-			// Always on finish propagate exec status.
-			defer func() { node.PropagateExecStatus() }()
-
-			if node.Execution.Cancel {
-				log.Debugf(
-					"Runner '%v' for target '%v' is cancelled by dependency.",
-					runnerIdx,
-					node.Target.ID,
-				)
-
-				return
-			} else if node.StatusAnyFailed() {
-				log.Debugf(
-					"Target '%v' is failed already. Skip runner '%v', step: '%v'.",
-					node.Target.ID,
-					runner.RunnerID,
-					stepIdx,
-				)
-
+			if !state.startRunner(node, step, status, &init) {
 				return
 			}
 
 			var err error
+			ctx := state.ctx
 			defer func() {
 				if r := recover(); r != nil {
 					err = errors.Combine(
@@ -196,36 +264,47 @@ func addRunnerTasks(
 						"Runner '%v' for target '%v' failed.",
 						runnerIdx,
 						node.Target.ID)
-					status.Error = err
-					status.Status = ExecStatusFailed
-				} else {
-					status.Status = ExecStatusSuccess
 				}
+				state.setResult(ctx, status, err)
 			}()
 
-			err = ExecuteRunner(
-				logger,
-				node.Comp,
-				node.Target.ID,
-				step.Index,
-				runnerIdx,
-				runner.Runner,
-				runner.Toolchain,
-				toolchainDispatcher,
-				config,
-				rootDir,
-			)
+			out, err := state.output.open(node, step.Index, runnerIdx)
+			if err != nil {
+				return
+			}
+			defer func() { err = errors.Combine(err, out.Close()) }()
+
+			depOutputs, err := node.DependencyOutputs()
+			if err != nil {
+				return
+			}
+
+			ctx, err = state.runAttempts(node, step, status,
+				func(ctx context.Context) error {
+					return ExecuteRunner(
+						out.context(ctx),
+						out.logger(node.Target.ID),
+						node.Comp,
+						node.Target.ID,
+						step.Index,
+						runnerIdx,
+						depOutputs,
+						runner.Runner,
+						runner.Toolchain,
+						toolchainDispatcher,
+						config,
+						rootDir,
+					)
+				})
 		}
 	}
 
 	runnerTasks := []*taskflow.Task{}
-	for runnerIdx, runner := range runners {
-		n := fmt.Sprintf("%v::step-%v::%v", node.Target.ID, step.Index, runnerIdx)
+	for i := range runners {
+		r := &runners[i]
+		n := fmt.Sprintf("%v::step-%v::%v", node.Target.ID, step.Index, r.runnerIdx)
 
-		logger := log.NewLogger(node.Target.ID.String())
-		status := node.Execution.AddRunnerStatus()
-
-		runnerTask := sf.NewTask(n, newTask(n, logger, runner, status, runnerIdx))
+		runnerTask := sf.NewTask(n, newTask(n, r.inst, r.status, r.runnerIdx))
 
 		runnerTasks = append(runnerTasks, runnerTask)
 	}
@@ -234,6 +313,4 @@ func addRunnerTasks(
 	for i := 1; i < len(runnerTasks); i++ {
 		runnerTasks[i].Succeed(runnerTasks[i-1])
 	}
-
-	return nil
 }
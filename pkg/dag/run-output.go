@@ -0,0 +1,287 @@
+package dag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/exec"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+type (
+	// runOutput collects the live output of all runners of one execution.
+	// Output is only written in complete lines, such that the
+	// output of concurrent runners does not interleave within lines.
+	runOutput struct {
+		// Where the live output is written to.
+		terminal io.Writer
+
+		// Pass the output through unprefixed and write the standard output
+		// of commands to `stdout` (`nil` if the output is prefixed).
+		stdout io.Writer
+
+		// Buffer the output of each target and print it in one block
+		// when the target finished.
+		buffered bool
+		buffers  map[target.ID]*bytes.Buffer
+
+		// The live progress view (`nil` if disabled).
+		progress *progressView
+
+		mutex sync.Mutex
+	}
+
+	// runnerOutput is the log sink of one runner.
+	// It writes all output to the runner's log file and
+	// each complete line prefixed with the target id to the live output.
+	runnerOutput struct {
+		out    *runOutput
+		id     target.ID
+		step   step.Index
+		start  time.Time
+		prefix []byte
+		file   *os.File
+
+		// The incomplete last line.
+		partial []byte
+		// The last non-empty line (read by the progress view).
+		last atomic.Pointer[string]
+
+		mutex sync.Mutex
+	}
+)
+
+// newRunOutput creates the output for the terminal `terminal`.
+// With a progress view `progress`, all output is printed above it.
+func newRunOutput(terminal io.Writer, buffered bool, progress *progressView) *runOutput {
+	if progress != nil {
+		terminal = progress
+	}
+
+	return &runOutput{
+		terminal: terminal,
+		buffered: buffered,
+		buffers:  make(map[target.ID]*bytes.Buffer),
+		progress: progress,
+	}
+}
+
+// newPassthroughOutput creates the output for serial execution which
+// only writes the log files and passes the output unchanged through to
+// the terminal `terminal` and the standard output `stdout`.
+func newPassthroughOutput(terminal io.Writer, stdout io.Writer) *runOutput {
+	return &runOutput{
+		terminal: terminal,
+		stdout:   stdout,
+		buffers:  make(map[target.ID]*bytes.Buffer),
+	}
+}
+
+// write writes the complete lines `lines` of target `id`.
+func (o *runOutput) write(id target.ID, lines []byte) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if !o.buffered {
+		_, _ = o.terminal.Write(lines)
+
+		return
+	}
+
+	buf := o.buffers[id]
+	if buf == nil {
+		buf = &bytes.Buffer{}
+		o.buffers[id] = buf
+	}
+	buf.Write(lines)
+}
+
+// finish prints the buffered output of the finished target on node `node`
+// in one block.
+func (o *runOutput) finish(node *TargetNode) {
+	if o == nil {
+		return
+	}
+
+	o.progress.finishTarget(node)
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	buf := o.buffers[node.Target.ID]
+	if buf == nil {
+		return
+	}
+	delete(o.buffers, node.Target.ID)
+
+	_, _ = o.terminal.Write(buf.Bytes())
+}
+
+// logFile returns the file where the output of runner `runnerIdx`
+// of step `stepIdx` is written to.
+func (n *TargetNode) logFile(stepIdx step.Index, runnerIdx int) string {
+	return n.Comp.OutLogsDir(n.Target.ID.Name(), fmt.Sprintf("step-%v-%v.log", stepIdx, runnerIdx))
+}
+
+// open opens the log sink of runner `runnerIdx` of step `stepIdx` on node `node`.
+// Returns `nil` if the output is not captured.
+func (o *runOutput) open(node *TargetNode, stepIdx step.Index, runnerIdx int) (*runnerOutput, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	file := node.logFile(stepIdx, runnerIdx)
+
+	err := os.MkdirAll(path.Dir(file), fs.DefaultPermissionsDir)
+	if err != nil {
+		return nil, errors.AddContext(err, "could not create log directory for target '%v'", node.Target.ID)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, errors.AddContext(err, "could not create log file for target '%v'", node.Target.ID)
+	}
+
+	r := &runnerOutput{
+		out:    o,
+		id:     node.Target.ID,
+		step:   stepIdx,
+		start:  time.Now(),
+		prefix: fmt.Appendf(nil, "%v │ ", node.Target.ID),
+		file:   f,
+	}
+	o.progress.addRunner(r)
+
+	return r, nil
+}
+
+// Write implements [io.Writer].
+func (r *runnerOutput) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	n, err := r.file.Write(p)
+
+	if r.out.stdout != nil {
+		r.out.write(r.id, p)
+
+		return n, err
+	}
+
+	r.partial = append(r.partial, p...)
+	if idx := bytes.LastIndexByte(r.partial, '\n'); idx >= 0 {
+		lines := r.partial[:idx+1]
+		r.setLastLine(lines)
+		r.out.write(r.id, r.prefixLines(lines))
+		r.partial = append(r.partial[:0], r.partial[idx+1:]...)
+	}
+
+	return n, err
+}
+
+// setLastLine remembers the last non-empty line in `lines`.
+func (r *runnerOutput) setLastLine(lines []byte) {
+	for l := range bytes.Lines(lines) {
+		if l = bytes.TrimSpace(l); len(l) != 0 {
+			s := string(l)
+			r.last.Store(&s)
+		}
+	}
+}
+
+// lastLine returns the last non-empty line written.
+func (r *runnerOutput) lastLine() string {
+	if l := r.last.Load(); l != nil {
+		return *l
+	}
+
+	return ""
+}
+
+func (r *runnerOutput) prefixLines(lines []byte) []byte {
+	var b bytes.Buffer
+	for line := range bytes.Lines(lines) {
+		b.Write(r.prefix)
+		b.Write(line)
+	}
+
+	return b.Bytes()
+}
+
+// logger returns the logger for the runner which writes to this sink.
+// Without a sink or when passed through, the logger has
+// the target id `id` as prefix.
+func (r *runnerOutput) logger(id target.ID) log.ILog {
+	switch {
+	case r == nil:
+		return log.NewLogger(id.String())
+	case r.out.stdout != nil:
+		return log.NewLoggerWithOutput(id.String(), r)
+	default:
+		return log.NewLoggerWithOutput("", r)
+	}
+}
+
+// context routes the output of all commands executed with the returned context
+// to this sink.
+// When passed through, the standard output of commands is kept separate.
+func (r *runnerOutput) context(ctx context.Context) context.Context {
+	switch {
+	case r == nil:
+		return ctx
+	case r.out.stdout != nil:
+		return exec.WithOutput(ctx, &runnerStdout{r}, r)
+	default:
+		return exec.WithOutput(ctx, r, r)
+	}
+}
+
+// runnerStdout writes the standard output of commands to the log file
+// of the runner and passes it through to the standard output.
+type runnerStdout struct {
+	r *runnerOutput
+}
+
+// Write implements [io.Writer].
+func (s *runnerStdout) Write(p []byte) (int, error) {
+	s.r.mutex.Lock()
+	defer s.r.mutex.Unlock()
+
+	n, err := s.r.file.Write(p)
+
+	s.r.out.mutex.Lock()
+	defer s.r.out.mutex.Unlock()
+	_, _ = s.r.out.stdout.Write(p)
+
+	return n, err
+}
+
+// Close writes the incomplete last line and closes the log file.
+func (r *runnerOutput) Close() error {
+	if r == nil {
+		return nil
+	}
+
+	r.out.progress.removeRunner(r)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.partial) != 0 {
+		r.out.write(r.id, r.prefixLines(append(r.partial, '\n')))
+		r.partial = nil
+	}
+
+	return r.file.Close()
+}
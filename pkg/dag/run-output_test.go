@@ -0,0 +1,159 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/exec"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOutputNode(t *testing.T, root string, name string) *TargetNode {
+	conf := &component.Config{
+		Name:     name,
+		Language: "go",
+		Targets: map[string]*target.Config{
+			"build": {Steps: []step.Config{{RunnerID: "test"}}},
+		},
+	}
+	require.NoError(t, conf.Init())
+	comp := component.NewComponent(conf, path.Join(root, name), "", "")
+
+	return &TargetNode{Comp: &comp, Target: conf.Targets["build"]}
+}
+
+func TestRunnerOutput(t *testing.T) {
+	t.Parallel()
+
+	for _, buffered := range []bool{false, true} {
+		root := t.TempDir()
+		a := newOutputNode(t, root, "a")
+		b := newOutputNode(t, root, "b")
+
+		var terminal strings.Builder
+		o := newRunOutput(&terminal, buffered, nil)
+
+		outA, err := o.open(a, 0, 0)
+		require.NoError(t, err)
+		outB, err := o.open(b, 0, 0)
+		require.NoError(t, err)
+
+		// Only complete lines are written.
+		_, err = outA.Write([]byte("hello "))
+		require.NoError(t, err)
+		_, err = outB.Write([]byte("other\n"))
+		require.NoError(t, err)
+		_, err = outA.Write([]byte("world\nlast"))
+		require.NoError(t, err)
+
+		require.NoError(t, outA.Close())
+		require.NoError(t, outB.Close())
+
+		if buffered {
+			// Nothing is printed before the targets finished.
+			assert.Empty(t, terminal.String())
+			o.finish(b)
+			o.finish(a)
+		}
+		assert.Equal(t, "b::build │ other\na::build │ hello world\na::build │ last\n",
+			terminal.String())
+
+		content, err := os.ReadFile(a.logFile(0, 0))
+		require.NoError(t, err)
+		assert.Equal(t, "hello world\nlast", string(content))
+	}
+}
+
+func TestPassthroughOutput(t *testing.T) {
+	t.Parallel()
+
+	a := newOutputNode(t, t.TempDir(), "a")
+
+	var terminal, stdout strings.Builder
+	o := newPassthroughOutput(&terminal, &stdout)
+
+	out, err := o.open(a, 0, 0)
+	require.NoError(t, err)
+
+	// The output is passed through unprefixed and without waiting for lines.
+	_, err = out.Write([]byte("hello "))
+	require.NoError(t, err)
+	assert.Equal(t, "hello ", terminal.String())
+
+	err = exec.NewCmdCtxBuilder().Context(out.context(t.Context())).Build().
+		Check("sh", "-c", "echo 'command output'")
+	require.NoError(t, err)
+	require.NoError(t, out.Close())
+
+	assert.Equal(t, "hello ", terminal.String())
+	assert.Equal(t, "command output\n", stdout.String())
+
+	content, err := os.ReadFile(a.logFile(0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "hello command output\n", string(content))
+}
+
+func TestExecuteCapturesOutput(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	targets, prios, err := DefineExecutionOrder(
+		[]*component.Component{newOutputNode(t, root, "a").Comp}, root)
+	require.NoError(t, err)
+
+	f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+		ctx.Log().Info("Log message.")
+
+		return exec.NewCmdCtxBuilder().Context(ctx.Ctx()).Build().
+			Check("sh", "-c", "echo 'command output' && echo 'command error' >&2")
+	})
+
+	for _, parallel := range []bool{false, true} {
+		err = Execute(targets, prios, f, nil, nil, root, parallel,
+			WithForce(true), WithBufferedOutput(parallel))
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(targets["a::build"].logFile(0, 0))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "Log message.")
+		assert.Contains(t, string(content), "command output\n")
+		assert.Contains(t, string(content), "command error\n")
+	}
+}
+
+func TestExecuteCancelKillsChildren(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	targets, prios, err := DefineExecutionOrder(
+		[]*component.Component{newOutputNode(t, root, "a").Comp}, root)
+	require.NoError(t, err)
+
+	// The background child holds the captured output open
+	// until the whole process group is killed.
+	f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+		return exec.NewCmdCtxBuilder().Context(ctx.Ctx()).Build().
+			Check("sh", "-c", "sleep 30 & wait")
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(300*time.Millisecond, cancel)
+
+	start := time.Now()
+	err = Execute(targets, prios, f, nil, nil, root, true,
+		WithForce(true), WithContext(ctx))
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
@@ -0,0 +1,264 @@
+package dag
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+	"github.com/sdsc-ordes/quitsh/pkg/ci"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+const progressInterval = 100 * time.Millisecond
+
+//nolint:gochecknoglobals // Constant.
+var progressSpinner = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+type (
+	// progressView is a live view of the execution at the bottom of the terminal:
+	// The running steps with their elapsed time and last output line, and
+	// the number of queued, finished and failed targets.
+	// All other output (also the global logger) is printed above the view.
+	progressView struct {
+		term   *os.File
+		styles progressStyles
+
+		// The number of targets to execute.
+		total    int
+		finished int
+		failed   int
+
+		// The open log sinks of all running runners.
+		running []*runnerOutput
+
+		// The number of lines of the drawn view.
+		lines int
+		frame int
+
+		restoreLog func()
+		done       chan struct{}
+		wg         sync.WaitGroup
+
+		mutex sync.Mutex
+	}
+
+	progressStyles struct {
+		header  lipgloss.Style
+		target  lipgloss.Style
+		elapsed lipgloss.Style
+		output  lipgloss.Style
+		failed  lipgloss.Style
+	}
+)
+
+// startProgressView starts the progress view for `total` targets on the terminal `f`.
+// Returns `nil` if `f` is not a terminal or CI is running, in which case
+// the plain logs are printed.
+// The caller must call [progressView.stop].
+func startProgressView(f *os.File, total int) *progressView {
+	if !term.IsTerminal(f.Fd()) || ci.IsRunning() {
+		return nil
+	}
+
+	r := lipgloss.NewRenderer(f)
+	p := &progressView{
+		term:  f,
+		total: total,
+		done:  make(chan struct{}),
+		styles: progressStyles{
+			header:  r.NewStyle().Bold(true),
+			target:  r.NewStyle().Foreground(lipgloss.Color("#58A6FF")),
+			elapsed: r.NewStyle().Faint(true),
+			output:  r.NewStyle().Faint(true),
+			failed:  r.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true),
+		},
+	}
+
+	p.restoreLog = log.SetOutput(p)
+	termenv.NewOutput(f).HideCursor()
+
+	p.wg.Go(func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.mutex.Lock()
+				p.frame++
+				p.redraw(nil)
+				p.mutex.Unlock()
+			}
+		}
+	})
+
+	return p
+}
+
+// stop stops the progress view and removes it from the terminal.
+func (p *progressView) stop() {
+	if p == nil {
+		return
+	}
+
+	close(p.done)
+	p.wg.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var b bytes.Buffer
+	p.clear(&b)
+	termenv.NewOutput(&b).ShowCursor()
+	_, _ = p.term.Write(b.Bytes())
+	p.lines = 0
+
+	p.restoreLog()
+}
+
+// Write prints `b` above the view.
+func (p *progressView) Write(b []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.redraw(b)
+
+	return len(b), nil
+}
+
+// addRunner adds the running runner with log sink `r`.
+func (p *progressView) addRunner(r *runnerOutput) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.running = append(p.running, r)
+}
+
+// removeRunner removes the finished runner with log sink `r`.
+func (p *progressView) removeRunner(r *runnerOutput) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.running = slices.DeleteFunc(p.running, func(o *runnerOutput) bool { return o == r })
+}
+
+// finishTarget counts the finished target on node `node`.
+func (p *progressView) finishTarget(node *TargetNode) {
+	if p == nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.finished++
+	if node.StatusAnyFailed() {
+		p.failed++
+	}
+}
+
+// redraw clears the view, prints `out` and draws the view again
+// in one write to avoid flickering.
+func (p *progressView) redraw(out []byte) {
+	var b bytes.Buffer
+	p.clear(&b)
+	b.Write(out)
+
+	lines := p.render()
+	for _, l := range lines {
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	p.lines = len(lines)
+
+	_, _ = p.term.Write(b.Bytes())
+}
+
+// clear writes the sequence to clear the drawn view to `b`.
+func (p *progressView) clear(b *bytes.Buffer) {
+	if p.lines == 0 {
+		return
+	}
+
+	// Move to the first line of the view and erase to the end of the display.
+	termenv.NewOutput(b).CursorPrevLine(p.lines)
+	fmt.Fprintf(b, termenv.CSI+termenv.EraseDisplaySeq, 0)
+}
+
+// render renders the lines of the view.
+func (p *progressView) render() []string {
+	width, height, err := term.GetSize(p.term.Fd())
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24 //nolint:mnd // Default terminal size.
+	}
+
+	targets := make(map[target.ID]struct{}, len(p.running))
+	for _, r := range p.running {
+		targets[r.id] = struct{}{}
+	}
+	queued := max(p.total-p.finished-len(targets), 0)
+
+	spinner := progressSpinner[p.frame%len(progressSpinner)]
+	header := fmt.Sprintf("%s running: %v │ queued: %v │ finished: %v/%v",
+		spinner, len(targets), queued, p.finished, p.total)
+
+	failed := ""
+	if p.failed != 0 {
+		failed = p.styles.failed.Render(fmt.Sprintf(" │ failed: %v", p.failed))
+	}
+
+	lines := []string{p.styles.header.Render(header) + failed}
+
+	// Keep the view smaller than the terminal.
+	shown := p.running
+	if maxShown := max(height/2, 1); len(shown) > maxShown {
+		shown = shown[:maxShown-1]
+	}
+
+	for _, r := range shown {
+		name := fmt.Sprintf("  %v (step %v)", r.id, r.step)
+		elapsed := fmt.Sprintf(" %v ", time.Since(r.start).Round(time.Second))
+
+		line := p.styles.target.Render(name) + p.styles.elapsed.Render(elapsed)
+		if rest := width - len([]rune(name)) - len([]rune(elapsed)) - 3; rest > 0 {
+			if last := truncateRunes(r.lastLine(), rest); last != "" {
+				line += p.styles.output.Render("│ " + last)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	if more := len(p.running) - len(shown); more != 0 {
+		lines = append(lines, p.styles.elapsed.Render(fmt.Sprintf("  … and %v more", more)))
+	}
+
+	return lines
+}
+
+// truncateRunes truncates `s` to `n` runes.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-1]) + "…"
+}
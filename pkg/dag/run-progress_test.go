@@ -0,0 +1,69 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressViewNoTerminal(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Create(path.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Nil(t, startProgressView(f, 1))
+}
+
+func TestProgressViewRender(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	a := newOutputNode(t, root, "a")
+	b := newOutputNode(t, root, "b")
+
+	f, err := os.Create(path.Join(root, "term"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	r := lipgloss.NewRenderer(f, termenv.WithProfile(termenv.Ascii))
+	p := &progressView{term: f, total: 3, styles: progressStyles{
+		header:  r.NewStyle(),
+		target:  r.NewStyle(),
+		elapsed: r.NewStyle(),
+		output:  r.NewStyle(),
+		failed:  r.NewStyle(),
+	}}
+
+	o := newRunOutput(nil, true, p)
+	out, err := o.open(a, 1, 0)
+	require.NoError(t, err)
+	_, err = out.Write([]byte("first\n" + strings.Repeat("x", 200) + "\n\n"))
+	require.NoError(t, err)
+
+	*b.Execution.AddRunnerStatus() = RunnerStatus{Status: ExecStatusFailed}
+	o.finish(b)
+
+	lines := p.render()
+	require.Len(t, lines, 2)
+	assert.Equal(t, "⠋ running: 1 │ queued: 1 │ finished: 1/3 │ failed: 1", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "  a::build (step 1) 0s │ xxx"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[1], "x…"), lines[1])
+	// The last column is kept free to not wrap.
+	assert.Len(t, []rune(lines[1]), 79)
+
+	// The target is queued until it finished.
+	require.NoError(t, out.Close())
+	lines = p.render()
+	assert.Equal(t, []string{"⠋ running: 0 │ queued: 2 │ finished: 1/3 │ failed: 1"}, lines)
+}
@@ -0,0 +1,244 @@
+package dag
+
+import (
+	"context"
+	stderr "errors"
+	"sync"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/debug"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/exec"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+// runState is the state shared by all runners of one execution.
+type runState struct {
+	// The execution context of all runners, cancelled on abort.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Cancel all runners as soon as one fails.
+	failFast bool
+
+	// The default timeout of targets without one.
+	timeout time.Duration
+
+	// The captured output of all runners (`nil` if not captured).
+	output *runOutput
+
+	// Limits the number of runners executing at the same time.
+	slots jobSlots
+
+	// Guards the execution status of all nodes (runner statuses, cancel flags, deadlines)
+	// which is shared by concurrently executing runners.
+	mutex sync.Mutex
+}
+
+// newRunState creates the execution state from the options `opt`.
+// The caller must call `cancel` at the end.
+func newRunState(opt *execOption) *runState {
+	ctx := opt.ctx
+	if ctx == nil {
+		ctx = exec.GlobalContext
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &runState{
+		ctx:      ctx,
+		cancel:   cancel,
+		failFast: opt.failFast,
+		timeout:  opt.timeout,
+		slots:    newJobSlots(opt.jobs),
+	}
+}
+
+// isCancelled tells if the execution is cancelled.
+func (s *runState) isCancelled() bool {
+	return s.ctx.Err() != nil
+}
+
+// targetTimeout returns the timeout of the target on node `node` (zero if none).
+func (s *runState) targetTimeout(node *TargetNode) time.Duration {
+	if node.Target.Timeout != 0 {
+		return node.Target.Timeout
+	}
+
+	return s.timeout
+}
+
+// startTarget starts the timeout of the target on node `node`
+// (see [target.Config.Timeout]). It must be called when the target starts,
+// i.e. after its locks are acquired and before its first step is scheduled.
+func (s *runState) startTarget(node *TargetNode) {
+	timeout := s.targetTimeout(node)
+	if timeout <= 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	node.Execution.deadline = time.Now().Add(timeout)
+}
+
+// runnerContext returns the context for a runner of step `step` on node `node`.
+// It expires on the target timeout (counted from the start of the target,
+// see [runState.startTarget]) or on the step timeout, whichever comes first.
+// [step.Config.Always] steps only expire on their step timeout, such that
+// they still run after the target timed out.
+// The caller must call the returned cancel function.
+func (s *runState) runnerContext(
+	node *TargetNode,
+	step *step.Config,
+) (context.Context, context.CancelFunc) {
+	ctx := s.ctx
+	cancels := []context.CancelFunc{}
+
+	timeout := s.targetTimeout(node)
+
+	s.mutex.Lock()
+	deadline := node.Execution.deadline
+	s.mutex.Unlock()
+	debug.Assert(timeout <= 0 || !deadline.IsZero(),
+		"Target must be started before its runners.", "target", node.Target.ID)
+
+	if timeout > 0 && !deadline.IsZero() && !step.Always {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadlineCause(ctx, deadline,
+			errors.New("target '%v' timed out after '%v'", node.Target.ID, timeout))
+		cancels = append(cancels, cancel)
+	}
+
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, step.Timeout,
+			errors.New("step '%v' of target '%v' timed out after '%v'",
+				step.Index, node.Target.ID, step.Timeout))
+		cancels = append(cancels, cancel)
+	}
+
+	return ctx, func() {
+		for i := len(cancels) - 1; i >= 0; i-- {
+			cancels[i]()
+		}
+	}
+}
+
+// setResult sets the result of a runner with error `err` on status `status`.
+// The runner ran with context `ctx` (see [runState.runnerContext]),
+// if it expired the timeout is reported as the error.
+// Runners which fail due to the cancelled execution are marked as cancelled.
+// With fail-fast a failing runner cancels the execution.
+func (s *runState) setResult(ctx context.Context, status *RunnerStatus, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if stderr.Is(ctx.Err(), context.DeadlineExceeded) && !s.isCancelled() {
+		err = errors.Combine(context.Cause(ctx), err)
+	}
+
+	switch {
+	case err == nil:
+		status.Status = ExecStatusSuccess
+	case s.isCancelled():
+		log.Debug("Runner cancelled.", "target", status.TargetID, "error", err)
+		status.Status = ExecStatusCancelled
+	default:
+		status.Status = ExecStatusFailed
+		status.Error = err
+
+		if s.failFast {
+			log.Warn("Fail-fast: Cancel all runners.", "failed target", status.TargetID)
+			s.cancel()
+		}
+	}
+}
+
+// startRunner initializes the status `status` of a runner of step `step` on node `node`
+// with `init` and tells if the runner should run.
+// Runners are skipped if the execution is cancelled, the target is cancelled by a
+// dependency, up-to-date, restored from the cache, or if an earlier step of the target
+// failed (unless the step is [step.Config.Always]).
+func (s *runState) startRunner(
+	node *TargetNode,
+	step *step.Config,
+	status *RunnerStatus,
+	init *RunnerStatus,
+) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	*status = *init
+
+	switch {
+	case s.isCancelled():
+		log.Debugf(
+			"Execution is cancelled. Skip runner '%v' for target '%v'.",
+			status.RunnerID,
+			node.Target.ID,
+		)
+		status.Status = ExecStatusCancelled
+	case node.Execution.Cancel:
+		log.Debugf(
+			"Target '%v' is cancelled by prev. target. Skip runner '%v'",
+			node.Target.ID,
+			status.RunnerID,
+		)
+	case node.StatusAnyFailed() && !step.Always:
+		log.Debugf(
+			"Target '%v' is failed already. Skip runner '%v', step: '%v'.",
+			node.Target.ID,
+			status.RunnerID,
+			step.Index,
+		)
+	case node.Execution.UpToDate:
+		log.Debugf(
+			"Target '%v' is up-to-date. Skip runner '%v', step: '%v'.",
+			node.Target.ID,
+			status.RunnerID,
+			step.Index,
+		)
+		status.Status = ExecStatusUpToDate
+	case node.Execution.Restored:
+		log.Debugf(
+			"Target '%v' is restored from cache. Skip runner '%v', step: '%v'.",
+			node.Target.ID,
+			status.RunnerID,
+			step.Index,
+		)
+		status.Status = ExecStatusRestored
+	default:
+		return true
+	}
+
+	return false
+}
+
+// finishTarget validates the outputs of node `node` after its last runner,
+// propagates its execution status forward and prints its buffered output.
+// It must only be called once all runners of the target are finished, since runners
+// which did not run yet would cancel the dependents.
+func (s *runState) finishTarget(node *TargetNode) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	node.checkOutputs()
+	node.PropagateExecStatus()
+	s.output.finish(node)
+}
+
+// checkCancelled returns an error if the execution got cancelled and
+// no other error `err` is reported.
+func (s *runState) checkCancelled(err error) error {
+	if err == nil && s.isCancelled() {
+		return errors.New("execution was cancelled")
+	}
+
+	return err
+}
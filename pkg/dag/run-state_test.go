@@ -0,0 +1,108 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStateKeepGoing(t *testing.T) {
+	t.Parallel()
+
+	s := newRunState(&execOption{ctx: context.Background()})
+	defer s.cancel()
+
+	var status RunnerStatus
+	s.setResult(s.ctx, &status, errors.New("failed"))
+	assert.EqualValues(t, ExecStatusFailed, status.Status)
+	require.Error(t, status.Error)
+	assert.False(t, s.isCancelled())
+
+	status = RunnerStatus{}
+	s.setResult(s.ctx, &status, nil)
+	assert.EqualValues(t, ExecStatusSuccess, status.Status)
+	require.NoError(t, s.checkCancelled(nil))
+}
+
+func TestRunStateFailFast(t *testing.T) {
+	t.Parallel()
+
+	s := newRunState(&execOption{ctx: context.Background(), failFast: true})
+	defer s.cancel()
+
+	var status RunnerStatus
+	s.setResult(s.ctx, &status, errors.New("failed"))
+	assert.EqualValues(t, ExecStatusFailed, status.Status)
+	assert.True(t, s.isCancelled())
+
+	// Runners failing afterwards are cancelled.
+	status = RunnerStatus{}
+	s.setResult(s.ctx, &status, errors.New("killed"))
+	assert.EqualValues(t, ExecStatusCancelled, status.Status)
+	require.NoError(t, status.Error)
+
+	require.Error(t, s.checkCancelled(nil))
+}
+
+func TestRunStateTimeout(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	node := nodes["a::build"]
+
+	s := newRunState(&execOption{ctx: context.Background(), timeout: time.Millisecond})
+	defer s.cancel()
+
+	s.startTarget(node)
+	ctx, cancel := s.runnerContext(node, &step.Config{})
+	defer cancel()
+	<-ctx.Done()
+
+	// The timeout fails the runner but does not cancel the execution.
+	var status RunnerStatus
+	s.setResult(ctx, &status, errors.New("killed"))
+	assert.EqualValues(t, ExecStatusFailed, status.Status)
+	require.ErrorContains(t, status.Error, "target 'a::build' timed out after '1ms'")
+	assert.False(t, s.isCancelled())
+
+	// The target deadline is kept for the following runners.
+	ctx, cancel = s.runnerContext(node, &step.Config{})
+	defer cancel()
+	require.Error(t, ctx.Err())
+
+	// Always steps still run after the target timed out.
+	ctx, cancel = s.runnerContext(node, &step.Config{Always: true})
+	defer cancel()
+	require.NoError(t, ctx.Err())
+}
+
+func TestRunStateStepTimeout(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	node := nodes["a::build"]
+
+	s := newRunState(&execOption{ctx: context.Background()})
+	defer s.cancel()
+
+	ctx, cancel := s.runnerContext(node, &step.Config{Index: 1, Timeout: time.Millisecond})
+	defer cancel()
+	<-ctx.Done()
+
+	var status RunnerStatus
+	s.setResult(ctx, &status, nil)
+	assert.EqualValues(t, ExecStatusFailed, status.Status)
+	require.ErrorContains(t, status.Error, "step '1' of target 'a::build' timed out")
+}
@@ -1,8 +1,13 @@
 package dag
 
 import (
+	"context"
 	"os"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/sdsc-ordes/quitsh/pkg/cache"
 	"github.com/sdsc-ordes/quitsh/pkg/component"
 	"github.com/sdsc-ordes/quitsh/pkg/component/step"
 	"github.com/sdsc-ordes/quitsh/pkg/component/target"
@@ -10,6 +15,7 @@ import (
 	"github.com/sdsc-ordes/quitsh/pkg/errors"
 	"github.com/sdsc-ordes/quitsh/pkg/exec/git"
 	"github.com/sdsc-ordes/quitsh/pkg/exec/nix"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
 	"github.com/sdsc-ordes/quitsh/pkg/log"
 	"github.com/sdsc-ordes/quitsh/pkg/runner"
 	"github.com/sdsc-ordes/quitsh/pkg/runner/factory"
@@ -30,17 +36,79 @@ type (
 
 	ExecArgs struct {
 		Tags []string `yaml:"tags"`
+
+		// Run all targets even if they are up-to-date.
+		Force bool `yaml:"force"`
+
+		// The location of the shared output cache (see [cache.NewBackend]).
+		Cache string `yaml:"cache"`
+		// Only restore from the cache but never upload to it.
+		CacheReadOnly bool `yaml:"cacheReadOnly"`
+
+		// Write a machine-readable report to this file (see [WriteReportFile]).
+		Report string `yaml:"report"`
+		// Write a Chrome trace of all runners to this file (see [WriteTraceFile]).
+		Trace string `yaml:"trace"`
+
+		// Only rerun the failed, not run and cancelled targets of the last run
+		// (see [LoadRerunTargets]).
+		RerunFailed bool `yaml:"rerunFailed"`
+
+		// Only execute the shard `k/n` of the selected targets (see [WithShard]).
+		Shard string `yaml:"shard"`
+		// The JSON report with the recorded durations to balance the shards
+		// (see [LoadShardDurations]).
+		ShardDurations string `yaml:"shardDurations"`
 	}
 
 	ExecuteOption func(*execOption) error
 
 	execOption struct {
 		Tags []tags.Tag
+
+		// Disables the up-to-date check over the target fingerprints.
+		force bool
+
+		// The parent context for the execution.
+		ctx context.Context
+		// Cancel all runners as soon as one fails.
+		failFast bool
+
+		// The default timeout of targets which do not set one.
+		timeout time.Duration
+
+		// The shared output cache.
+		cache         cache.ICacheBackend
+		cacheReadOnly bool
+
+		// The maximal number of runners executing at the same time
+		// (only for concurrent execution). Defaults to the CPU count.
+		jobs int
+
+		// The file to write the execution report to.
+		reportFile string
+		// The file to write the Chrome trace to.
+		traceFile string
+		// The file to store the last run to.
+		lastRunFile string
+
+		// Buffer the output of each target and print it in one block
+		// when the target finished.
+		bufferOutput bool
+		// Show the live progress view on parallel execution.
+		progress bool
 	}
 )
 
 // Execute executes the DAG.
 // If no dispatcher is given, the toolchain dispatch is not done.
+// Targets which are up-to-date (see [ComputeFingerprints]) are skipped
+// unless [WithForce] is given.
+// Targets with outputs are restored from the cache given by [WithCache]
+// instead of running, and uploaded to it after they ran successfully.
+// The output of each runner is written to a log file in [component.Component.OutLogsDir].
+// On parallel execution (or with [WithBufferedOutput]) the live output is
+// prefixed with the target id.
 func Execute(
 	targets TargetNodeMap,
 	prios Priorities,
@@ -50,44 +118,157 @@ func Execute(
 	rootDir string,
 	parallel bool,
 	opts ...ExecuteOption,
-) error {
+) (err error) {
+	opt := execOption{}
+	if e := opt.Apply(opts...); e != nil {
+		return e
+	}
+
+	// Runners only get absolute directories, they never
+	// depend on the process working directory.
+	rootDir = fs.MakeAbsolute(rootDir)
+
+	if !opt.force || opt.cache != nil {
+		err = ComputeFingerprints(targets, opt.Tags)
+		if err != nil {
+			return errors.AddContext(err, "could not compute target fingerprints")
+		}
+		defer storeFingerprints(targets)
+	}
+
+	if !opt.force {
+		err = markUpToDate(targets)
+		if err != nil {
+			return err
+		}
+
+		if opt.cache != nil {
+			restoreFromCache(targets, opt.cache)
+		}
+	}
+
+	if opt.cache != nil && !opt.cacheReadOnly {
+		defer uploadToCache(targets, opt.cache)
+	}
+
+	state := newRunState(&opt)
+	defer state.cancel()
+
+	var progress *progressView
+	if parallel && opt.progress {
+		progress = startProgressView(os.Stderr, len(targets))
+		defer progress.stop()
+	}
+
+	// Capture the output of each runner in its log file and prefix the live output
+	// with the target id, since concurrent runners would interleave.
+	if parallel || opt.bufferOutput {
+		state.output = newRunOutput(os.Stderr, opt.bufferOutput, progress)
+	} else {
+		state.output = newPassthroughOutput(os.Stderr, os.Stdout)
+	}
+
 	if parallel {
-		return executeConcurrent(
+		err = executeConcurrent(
+			state,
 			targets,
 			runnerFactory,
 			dispatcher,
 			config,
-			rootDir, opts...)
+			rootDir, &opt)
 	} else {
-		return executeNormal(
+		err = executeNormal(
+			state,
 			prios,
 			runnerFactory,
 			dispatcher,
 			config,
-			rootDir, opts...,
+			rootDir, &opt,
 		)
 	}
+
+	logTimings(targets)
+
+	if opt.reportFile != "" {
+		e := WriteReportFile(opt.reportFile, targets)
+		err = errors.Combine(err, e)
+	}
+
+	if opt.traceFile != "" {
+		e := WriteTraceFile(opt.traceFile, targets)
+		err = errors.Combine(err, e)
+	}
+
+	if opt.lastRunFile != "" {
+		e := storeLastRun(opt.lastRunFile, targets)
+		err = errors.Combine(err, e)
+	}
+
+	return err
+}
+
+// markUpToDate marks all targets as up-to-date which match the fingerprint
+// of their last successful run and whose outputs exist.
+// The stored fingerprints of all other targets are removed, such that
+// a failing run does not leave a stale fingerprint behind.
+func markUpToDate(targets TargetNodeMap) error {
+	for _, n := range targets {
+		last, e := n.LoadFingerprint()
+		if e != nil {
+			return e
+		}
+
+		if last != "" && last == n.Fingerprint {
+			_, missing, e := n.ResolveOutputs()
+			if e != nil {
+				return e
+			}
+
+			if len(missing) == 0 {
+				log.Info("Target is up-to-date.", "target", n.Target.ID)
+				n.Execution.UpToDate = true
+
+				continue
+			}
+
+			log.Info("Target outputs are missing.", "target", n.Target.ID, "outputs", missing)
+		}
+
+		e = os.RemoveAll(n.fingerprintFile())
+		if e != nil {
+			return errors.AddContext(e,
+				"could not remove fingerprint of target '%v'", n.Target.ID)
+		}
+	}
+
+	return nil
+}
+
+// storeFingerprints stores the fingerprints of all targets which ran successfully.
+func storeFingerprints(targets TargetNodeMap) {
+	for _, n := range targets {
+		if n.Execution.UpToDate || n.Execution.Cancel ||
+			n.Status() != ExecStatusSuccess {
+			continue
+		}
+
+		e := n.StoreFingerprint()
+		log.WarnE(e, "Could not store fingerprint.", "target", n.Target.ID)
+	}
 }
 
 // executeNormal executes the DAG non-concurrent.
 // If no dispatcher is given, the toolchain dispatch is not done.
 func executeNormal(
+	state *runState,
 	prios Priorities,
 	runnerFactory factory.IFactory,
 	toolchainDispatcher toolchain.IDispatcher,
 	config config.IConfig,
 	rootDir string,
-	opts ...ExecuteOption,
+	opt *execOption,
 ) error {
-	opt := execOption{}
-	if e := opt.Apply(opts...); e != nil {
-		return e
-	}
-
-	currCwd, err := os.Getwd()
-	log.PanicE(err, "Cannot get working dir.")
-	defer func() { _ = os.Chdir(currCwd) }()
-
+	var err error
 	allRunners := []RunnerData{}
 
 	addRunners := func(node *TargetNode, step *step.Config, stepIdx int) {
@@ -104,21 +285,8 @@ func executeNormal(
 			return
 		}
 
-		if step.RunnerID != "" {
-			runners, e = runnerFactory.CreateByID(
-				step.RunnerID, step.Toolchain, step.ConfigRaw)
-		} else if step.Runner != "" {
-			runners, e = runnerFactory.CreateByKey(
-				runner.NewRegisterKey(node.Target.Stage, step.Runner),
-				step.Toolchain,
-				step.ConfigRaw,
-			)
-		}
-
+		runners, e = createRunners(runnerFactory, node, step)
 		if e != nil {
-			e = errors.AddContext(e,
-				"could not instantiate runner for target '%v'",
-				node.Target.ID)
 			err = errors.Combine(err, e)
 
 			return
@@ -140,8 +308,8 @@ func executeNormal(
 
 	for _, prio := range prios {
 		for _, node := range prio.Nodes {
-			for stepIdx, step := range node.Target.Steps {
-				addRunners(node, &step, stepIdx)
+			for _, stepIdx := range stepOrder(node.Target) {
+				addRunners(node, &node.Target.Steps[stepIdx], stepIdx)
 			}
 		}
 	}
@@ -151,6 +319,7 @@ func executeNormal(
 	}
 
 	return executeRunners(
+		state,
 		allRunners,
 		toolchainDispatcher,
 		config,
@@ -159,6 +328,7 @@ func executeNormal(
 }
 
 func executeRunners(
+	state *runState,
 	allRunners []RunnerData,
 	toolchainDispatcher toolchain.IDispatcher,
 	config config.IConfig,
@@ -168,73 +338,239 @@ func executeRunners(
 
 	var summary Summary
 
-	for _, rD := range allRunners {
-		*rD.status = RunnerStatus{
-			ExecStatusNotRun,
-			nil,
-			rD.comp.Name(),
-			rD.targetID,
-			rD.step.Index,
-			rD.inst.RunnerID,
-		}
-
-		switch {
-		case rD.node.Execution.Cancel:
-			log.Debugf(
-				"Target '%v' is cancelled by prev. target. Skip runner '%v'",
-				rD.node.Target.ID,
-				rD.inst.RunnerID,
-			)
-		case rD.node.StatusAnyFailed():
-			log.Debugf(
-				"Target '%v' is failed already. Skip runner '%v', step: '%v'.",
-				rD.node.Target.ID,
+	run := func(rD *RunnerData) {
+		init := RunnerStatus{
+			Status:    ExecStatusNotRun,
+			CompName:  rD.comp.Name(),
+			TargetID:  rD.targetID,
+			StepIdx:   rD.step.Index,
+			RunnerID:  rD.inst.RunnerID,
+			Toolchain: rD.inst.Toolchain,
+
+			Dispatched: isDispatched(rD.inst.Toolchain, toolchainDispatcher),
+		}
+
+		if !state.startRunner(rD.node, rD.step, rD.status, &init) {
+			return
+		}
+
+		log.Info("Starting runner.", "runner", rD.inst.RunnerID, "target", rD.targetID)
+
+		ctx := state.ctx
+		out, e := state.output.open(rD.node, rD.step.Index, rD.runnerIdx)
+
+		var depOutputs map[target.ID][]string
+		if e == nil {
+			depOutputs, e = rD.node.DependencyOutputs()
+		}
+
+		if e == nil {
+			ctx, e = state.runAttempts(rD.node, rD.step, rD.status,
+				func(ctx context.Context) error {
+					return ExecuteRunner(
+						out.context(ctx),
+						out.logger(rD.targetID),
+						rD.comp,
+						rD.targetID,
+						rD.step.Index,
+						rD.runnerIdx,
+						depOutputs,
+						rD.inst.Runner,
+						rD.inst.Toolchain,
+						toolchainDispatcher,
+						config,
+						rootDir,
+					)
+				})
+		}
+		e = errors.Combine(e, out.Close())
+
+		if e != nil {
+			e = errors.AddContext(e,
+				"Runner '%v' for target '%v' failed.",
 				rD.inst.RunnerID,
-				rD.step.Index,
-			)
-		default:
-			log.Info("Starting runner.", "runner", rD.inst.RunnerID, "target", rD.targetID)
-
-			e := ExecuteRunner(
-				log.NewLogger(rD.targetID.String()),
-				rD.comp,
-				rD.targetID,
-				rD.step.Index,
-				rD.runnerIdx,
-				rD.inst.Runner,
-				rD.inst.Toolchain,
-				toolchainDispatcher,
-				config,
-				rootDir,
-			)
+				rD.targetID)
+		}
+		state.setResult(ctx, rD.status, e)
+	}
 
-			if e != nil {
-				e = errors.AddContext(e,
-					"Runner '%v' for target '%v' failed.",
-					rD.inst.RunnerID,
-					rD.targetID)
-				rD.status.Error = e
-				rD.status.Status = ExecStatusFailed
-			} else {
-				rD.status.Status = ExecStatusSuccess
-			}
+	for len(allRunners) != 0 {
+		batch := nextRunnerBatch(allRunners)
+		allRunners = allRunners[len(batch):]
+
+		// Start the target before its first runner.
+		if rs := batch[0].node.Execution.Runners; rs[0] == batch[0].status {
+			state.startTarget(batch[0].node)
 		}
 
-		summary.AddStatus(rD.status)
-		rD.node.PropagateExecStatus()
+		runRunnerBatch(batch, run)
+
+		for i := range batch {
+			rD := &batch[i]
+
+			// Finish the target after its last runner.
+			if rs := rD.node.Execution.Runners; rs[len(rs)-1] == rD.status {
+				state.finishTarget(rD.node)
+			}
+
+			summary.AddStatus(rD.status)
+		}
 	}
 
 	summary.Log()
 
-	return summary.allErrors
+	return state.checkCancelled(summary.allErrors)
+}
+
+// nextRunnerBatch returns the runners at the start of `runners` which run together:
+// All runners of the steps of a parallel target (see [target.Config.Parallel])
+// which are not [step.Config.Always], otherwise only the first runner.
+func nextRunnerBatch(runners []RunnerData) []RunnerData {
+	first := &runners[0]
+	if !first.node.Target.Parallel || first.step.Always {
+		return runners[:1]
+	}
+
+	n := 1
+	for n < len(runners) && runners[n].node == first.node && !runners[n].step.Always {
+		n++
+	}
+
+	return runners[:n]
+}
+
+// runRunnerBatch runs all steps in `batch` concurrently with `run`.
+// The runners of one step run one after another.
+func runRunnerBatch(batch []RunnerData, run func(rD *RunnerData)) {
+	if len(batch) == 1 {
+		run(&batch[0])
+
+		return
+	}
+
+	var wg sync.WaitGroup
+	for start := 0; start < len(batch); {
+		end := start + 1
+		for end < len(batch) && batch[end].step.Index == batch[start].step.Index {
+			end++
+		}
+
+		stepRunners := batch[start:end]
+		wg.Go(func() {
+			for i := range stepRunners {
+				run(&stepRunners[i])
+			}
+		})
+
+		start = end
+	}
+	wg.Wait()
+}
+
+// stepOrder returns the step indices of target `t` in execution order.
+// For parallel targets (see [target.Config.Parallel]) the
+// [step.Config.Always] steps come last.
+func stepOrder(t *target.Config) []int {
+	order := make([]int, len(t.Steps))
+	for i := range order {
+		order[i] = i
+	}
+
+	if t.Parallel {
+		slices.SortStableFunc(order, func(a, b int) int {
+			switch {
+			case !t.Steps[a].Always && t.Steps[b].Always:
+				return -1
+			case t.Steps[a].Always && !t.Steps[b].Always:
+				return 1
+			}
+
+			return 0
+		})
+	}
+
+	return order
 }
 
+// stepPredecessors returns for each step of target `t` the indices of the steps it
+// waits for: The previous step, or for parallel targets (see [target.Config.Parallel]),
+// nothing for normal steps and all normal steps and the previous
+// [step.Config.Always] step for always steps.
+func stepPredecessors(t *target.Config) [][]int {
+	preds := make([][]int, len(t.Steps))
+
+	if !t.Parallel {
+		for i := 1; i < len(t.Steps); i++ {
+			preds[i] = []int{i - 1}
+		}
+
+		return preds
+	}
+
+	var normal []int
+	for i := range t.Steps {
+		if !t.Steps[i].Always {
+			normal = append(normal, i)
+		}
+	}
+
+	prevAlways := -1
+	for i := range t.Steps {
+		if !t.Steps[i].Always {
+			continue
+		}
+
+		preds[i] = slices.Clone(normal)
+		if prevAlways >= 0 {
+			preds[i] = append(preds[i], prevAlways)
+		}
+		prevAlways = i
+	}
+
+	return preds
+}
+
+// createRunners instantiates the runners of step `step` on node `node`.
+func createRunners(
+	runnerFactory factory.IFactory,
+	node *TargetNode,
+	step *step.Config,
+) (runners []factory.RunnerInstance, err error) {
+	if step.RunnerID != "" {
+		runners, err = runnerFactory.CreateByID(
+			step.RunnerID, step.Toolchain, step.ConfigRaw)
+	} else if step.Runner != "" {
+		runners, err = runnerFactory.CreateByKey(
+			runner.NewRegisterKey(node.Target.Stage, step.Runner),
+			step.Toolchain,
+			step.ConfigRaw,
+		)
+	}
+
+	if err != nil {
+		return nil, errors.AddContext(err,
+			"could not instantiate runner for target '%v'", node.Target.ID)
+	}
+
+	return runners, nil
+}
+
+// isDispatched tells if a runner in toolchain `toolchainName` is dispatched
+// over the toolchain dispatcher `dispatcher`.
+func isDispatched(toolchainName string, dispatcher toolchain.IDispatcher) bool {
+	return dispatcher != nil && !nix.HaveToolchain(toolchainName)
+}
+
+// ExecuteRunner executes the runner `runner` directly or
+// over the toolchain dispatcher. The context `ctx` cancels the execution.
 func ExecuteRunner(
+	ctx context.Context,
 	log log.ILog,
 	comp *component.Component,
 	targetID target.ID,
 	stepIdx step.Index,
 	runnerIdx int,
+	depOutputs map[target.ID][]string,
 	runner runner.IRunner,
 	toolchainName string,
 	toolchainDispatcher toolchain.IDispatcher,
@@ -267,21 +603,23 @@ func ExecuteRunner(
 	}
 
 	if noDispatch { //nolint: nestif,nolintlint
-		// Change to repo root and run the runner.
-		err := os.Chdir(rootDir)
-		if err != nil {
-			return err
-		}
-
-		ctx := context{
+		// Note: The process working directory is never changed since runners
+		// execute concurrently. Runners use `Root()` or the component's root instead.
+		rCtx := runnerContext{
+			ctx:       ctx,
 			gitx:      git.NewCtx(rootDir),
 			comp:      comp,
 			targetID:  targetID,
 			toolchain: toolchainName,
 			stepIdx:   stepIdx,
 			log:       log,
+
+			depOutputs: depOutputs,
 		}
-		err = runner.Run(&ctx)
+		if t := comp.Config().TargetByID(targetID); t != nil {
+			rCtx.params = t.Parameters
+		}
+		err := runner.Run(&rCtx)
 
 		if err != nil {
 			log.ErrorE(err, "Runner not successful.", "runner", runner.ID(), "target", targetID)
@@ -303,16 +641,22 @@ func ExecuteRunner(
 			StepIndex:    stepIdx,
 			RunnerIndex:  runnerIdx,
 			RunnerID:     runner.ID(),
-			Toolchain:    toolchainName}
-		err := toolchainDispatcher.Run(rootDir, &dArgs, config)
+			Toolchain:    toolchainName,
+
+			DependencyOutputs: depOutputs,
+		}
+		start := time.Now()
+		err := toolchainDispatcher.Run(ctx, rootDir, &dArgs, config)
 
 		if err != nil {
-			log.ErrorE(err, "Toolchain dispatch failed.", "runner", runner.ID(), "target", targetID)
+			log.ErrorE(err, "Toolchain dispatch failed.",
+				"runner", runner.ID(), "target", targetID, "duration", time.Since(start))
 
 			return err
 		}
 
-		log.Info("Toolchain dispatch successful.", "runner", runner.ID(), "target", targetID)
+		log.Info("Toolchain dispatch successful.",
+			"runner", runner.ID(), "target", targetID, "duration", time.Since(start))
 	}
 
 	return nil
@@ -330,6 +674,149 @@ func (o *execOption) Apply(opts ...ExecuteOption) error {
 	return nil
 }
 
+// WithForce disables the up-to-date check such that all targets run.
+func WithForce(force bool) ExecuteOption {
+	return func(o *execOption) error {
+		o.force = force
+
+		return nil
+	}
+}
+
+// WithCache sets the shared output cache `c`.
+// If `readOnly` is set, outputs are only restored but never uploaded.
+func WithCache(c cache.ICacheBackend, readOnly bool) ExecuteOption {
+	return func(o *execOption) error {
+		o.cache = c
+		o.cacheReadOnly = readOnly
+
+		return nil
+	}
+}
+
+// WithCacheLocation sets the shared output cache by its location
+// (see [cache.NewBackend]). An empty location disables the cache.
+func WithCacheLocation(loc string, readOnly bool) ExecuteOption {
+	return func(o *execOption) error {
+		if loc == "" {
+			return nil
+		}
+
+		c, err := cache.NewBackend(loc)
+		if err != nil {
+			return err
+		}
+
+		o.cache = c
+		o.cacheReadOnly = readOnly
+
+		return nil
+	}
+}
+
+// WithContext sets the parent context `ctx` of the execution.
+// Defaults to [exec.GlobalContext] or `context.Background()`.
+func WithContext(ctx context.Context) ExecuteOption {
+	return func(o *execOption) error {
+		o.ctx = ctx
+
+		return nil
+	}
+}
+
+// WithFailFast cancels all running and pending runners as soon as one fails.
+// Otherwise (default: keep-going) only the dependents of a failed target are
+// not executed.
+func WithFailFast(failFast bool) ExecuteOption {
+	return func(o *execOption) error {
+		o.failFast = failFast
+
+		return nil
+	}
+}
+
+// WithBufferedOutput buffers the output of each target and prints it
+// in one block when the target finished.
+func WithBufferedOutput(buffered bool) ExecuteOption {
+	return func(o *execOption) error {
+		o.bufferOutput = buffered
+
+		return nil
+	}
+}
+
+// WithProgress shows a live progress view of the running targets on parallel execution.
+// Plain logs are printed if the standard error is not a terminal or CI is running.
+func WithProgress(enable bool) ExecuteOption {
+	return func(o *execOption) error {
+		o.progress = enable
+
+		return nil
+	}
+}
+
+// WithTimeout sets the default timeout `timeout` for targets which do not
+// define one. Zero disables the default timeout.
+func WithTimeout(timeout time.Duration) ExecuteOption {
+	return func(o *execOption) error {
+		if timeout < 0 {
+			return errors.New("timeout must not be negative: '%v'", timeout)
+		}
+		o.timeout = timeout
+
+		return nil
+	}
+}
+
+// WithReport writes a machine-readable report of all runners to the file `path`
+// after the execution (see [WriteReportFile]). An empty path disables the report.
+func WithReport(path string) ExecuteOption {
+	return func(o *execOption) error {
+		if path != "" {
+			if _, err := ReportFormatFromPath(path); err != nil {
+				return err
+			}
+		}
+		o.reportFile = path
+
+		return nil
+	}
+}
+
+// WithTrace writes a Chrome trace of all runners to the file `path`
+// after the execution (see [WriteTraceFile]). An empty path disables the trace.
+func WithTrace(path string) ExecuteOption {
+	return func(o *execOption) error {
+		o.traceFile = path
+
+		return nil
+	}
+}
+
+// WithLastRunFile stores all runner statuses to the file `path` after the execution
+// to rerun failed targets later (see [LoadRerunTargets] and [LastRunFile]).
+// The statuses are merged into the stored ones, such that executing other
+// targets afterwards keeps the failures of this execution.
+// An empty path disables storing the last run.
+func WithLastRunFile(path string) ExecuteOption {
+	return func(o *execOption) error {
+		o.lastRunFile = path
+
+		return nil
+	}
+}
+
+// WithJobs sets the maximal number of runners executing at the same time
+// for the concurrent execution and the parallel steps of a target
+// (see [target.Config.Parallel]). Values `<= 0` default to the CPU count.
+func WithJobs(jobs int) ExecuteOption {
+	return func(o *execOption) error {
+		o.jobs = jobs
+
+		return nil
+	}
+}
+
 // WithTags adds executable tags [tags.Tag] to the executable options.
 func WithTags(tag ...string) ExecuteOption {
 	return func(o *execOption) error {
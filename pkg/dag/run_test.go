@@ -0,0 +1,401 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component"
+	"github.com/sdsc-ordes/quitsh/pkg/component/step"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/runner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateAlwaysComps(t *testing.T, root string) []*component.Component {
+	// Step 0 fails, step 1 is skipped, step 2 and 3 always run.
+	steps := []step.Config{
+		{RunnerID: "test"},
+		{RunnerID: "test"},
+		{RunnerID: "test", Always: true},
+		{RunnerID: "test", Always: true},
+	}
+
+	conf := &component.Config{
+		Name:     "a",
+		Language: "go",
+		Targets: map[string]*target.Config{
+			"test":   {Steps: steps},
+			"deploy": {Dependencies: []target.ID{"self::test"}, Steps: slices.Clone(steps[2:3])},
+		},
+	}
+	require.NoError(t, conf.Init())
+	comp := component.NewComponent(conf, root, "", "")
+
+	return []*component.Component{&comp}
+}
+
+func TestExecuteAlwaysSteps(t *testing.T) {
+	t.Parallel()
+
+	for _, parallel := range []bool{false, true} {
+		root := t.TempDir()
+
+		targets, prios, err := DefineExecutionOrder(generateAlwaysComps(t, root), root)
+		require.NoError(t, err)
+
+		var mutex sync.Mutex
+		var ran []string
+		f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			ran = append(ran, fmt.Sprintf("%v:%v", ctx.Target(), ctx.Step()))
+
+			switch ctx.Step() {
+			case 0:
+				return errors.New("original failure")
+			case 3:
+				return errors.New("cleanup failure")
+			}
+
+			return nil
+		})
+
+		err = Execute(targets, prios, f, nil, nil, root, parallel, WithForce(true))
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "original failure")
+		assert.ErrorContains(t, err, "cleanup failure")
+
+		// The dependent target is not started.
+		assert.Equal(t, []string{"a::test:0", "a::test:2", "a::test:3"}, ran)
+
+		rs := targets["a::test"].Execution.Runners
+		require.Len(t, rs, 4)
+		assert.EqualValues(t, ExecStatusFailed, rs[0].Status)
+		assert.EqualValues(t, ExecStatusNotRun, rs[1].Status)
+		assert.EqualValues(t, ExecStatusSuccess, rs[2].Status)
+		assert.EqualValues(t, ExecStatusFailed, rs[3].Status)
+		assert.ErrorContains(t, rs[0].Error, "original failure")
+	}
+}
+
+func TestExecuteAlwaysStepsAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	for _, parallel := range []bool{false, true} {
+		root := t.TempDir()
+
+		targets, prios, err := DefineExecutionOrder(generateAlwaysComps(t, root), root)
+		require.NoError(t, err)
+
+		var mutex sync.Mutex
+		var ran []string
+		f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+			if ctx.Step() == 0 {
+				<-ctx.Ctx().Done()
+
+				return ctx.Ctx().Err()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			ran = append(ran, fmt.Sprintf("%v:%v", ctx.Target(), ctx.Step()))
+
+			return ctx.Ctx().Err()
+		})
+
+		err = Execute(targets, prios, f, nil, nil, root, parallel,
+			WithForce(true), WithTimeout(50*time.Millisecond))
+		require.ErrorContains(t, err, "target 'a::test' timed out")
+
+		// The always steps run after the timeout.
+		assert.Equal(t, []string{"a::test:2", "a::test:3"}, ran)
+
+		rs := targets["a::test"].Execution.Runners
+		require.Len(t, rs, 4)
+		assert.EqualValues(t, ExecStatusFailed, rs[0].Status)
+		assert.EqualValues(t, ExecStatusSuccess, rs[2].Status)
+		assert.EqualValues(t, ExecStatusSuccess, rs[3].Status)
+	}
+}
+
+func TestExecuteParallelSteps(t *testing.T) {
+	t.Parallel()
+
+	for _, parallel := range []bool{false, true} {
+		root := t.TempDir()
+
+		conf := &component.Config{
+			Name:     "a",
+			Language: "go",
+			Targets: map[string]*target.Config{
+				"lint": {
+					Parallel: true,
+					Steps: []step.Config{
+						{RunnerID: "test", Always: true},
+						{RunnerID: "test"},
+						{RunnerID: "test"},
+						{RunnerID: "test"},
+					},
+				},
+			},
+		}
+		require.NoError(t, conf.Init())
+		comp := component.NewComponent(conf, root, "", "")
+
+		targets, prios, err := DefineExecutionOrder([]*component.Component{&comp}, root)
+		require.NoError(t, err)
+
+		// All normal steps must run at the same time,
+		// the always step after them.
+		var running sync.WaitGroup
+		running.Add(3)
+		var finished atomic.Int32
+
+		f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+			if ctx.Step() == 0 {
+				if finished.Load() != 3 {
+					return errors.New("always step ran before the others")
+				}
+
+				return nil
+			}
+			defer finished.Add(1)
+
+			running.Done()
+			running.Wait()
+
+			if ctx.Step() == 2 {
+				return errors.New("lint failure")
+			}
+
+			return nil
+		})
+
+		err = Execute(targets, prios, f, nil, nil, root, parallel,
+			WithForce(true), WithJobs(4))
+		require.ErrorContains(t, err, "lint failure")
+
+		rs := targets["a::lint"].Execution.Runners
+		require.Len(t, rs, 4)
+		for _, r := range rs {
+			if r.StepIdx == 2 {
+				assert.EqualValues(t, ExecStatusFailed, r.Status)
+			} else {
+				assert.EqualValues(t, ExecStatusSuccess, r.Status, "step %v", r.StepIdx)
+			}
+		}
+	}
+}
+
+// peakCounter records the peak number of runners running at the same time.
+type peakCounter struct {
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+// run counts a runner running for `d`.
+func (c *peakCounter) run(d time.Duration) {
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+
+	for {
+		p := c.peak.Load()
+		if n <= p || c.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+
+	time.Sleep(d)
+}
+
+func generateIndependentComps(t *testing.T, root string, n int) []*component.Component {
+	comps := make([]*component.Component, 0, n)
+
+	for i := range n {
+		conf := &component.Config{
+			Name:     fmt.Sprintf("c%v", i),
+			Language: "go",
+			Targets: map[string]*target.Config{
+				"build": {Steps: []step.Config{{RunnerID: "test"}, {RunnerID: "test"}}},
+			},
+		}
+		require.NoError(t, conf.Init())
+		comp := component.NewComponent(conf, path.Join(root, conf.Name), "", "")
+		comps = append(comps, &comp)
+	}
+
+	return comps
+}
+
+func TestExecuteConcurrentJobs(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	targets, prios, err := DefineExecutionOrder(generateIndependentComps(t, root, 6), root)
+	require.NoError(t, err)
+
+	var counter peakCounter
+	var count atomic.Int32
+	f := newTestFactory(t, "test", func(runner.IContext) error {
+		count.Add(1)
+		counter.run(30 * time.Millisecond)
+
+		return nil
+	})
+
+	// Never more runners at the same time than jobs across all targets.
+	err = Execute(targets, prios, f, nil, nil, root, true, WithForce(true), WithJobs(2))
+	require.NoError(t, err)
+	assert.EqualValues(t, 12, count.Load())
+	assert.EqualValues(t, 2, counter.peak.Load())
+}
+
+func TestExecuteParallelStepsJobs(t *testing.T) {
+	t.Parallel()
+
+	for _, jobs := range []int{1, 3} {
+		root := t.TempDir()
+
+		conf := &component.Config{
+			Name:     "a",
+			Language: "go",
+			Targets: map[string]*target.Config{
+				"lint": {
+					Parallel: true,
+					Steps:    []step.Config{{RunnerID: "test"}, {RunnerID: "test"}, {RunnerID: "test"}},
+				},
+			},
+		}
+		require.NoError(t, conf.Init())
+		comp := component.NewComponent(conf, root, "", "")
+
+		targets, prios, err := DefineExecutionOrder([]*component.Component{&comp}, root)
+		require.NoError(t, err)
+
+		var counter peakCounter
+		f := newTestFactory(t, "test", func(runner.IContext) error {
+			counter.run(50 * time.Millisecond)
+
+			return nil
+		})
+
+		// The parallel steps in the serial execution are bound by the jobs.
+		err = Execute(targets, prios, f, nil, nil, root, false, WithForce(true), WithJobs(jobs))
+		require.NoError(t, err)
+		assert.EqualValues(t, jobs, counter.peak.Load())
+	}
+}
+
+func generateDependentComps(t *testing.T, root string, n int) []*component.Component {
+	comps := make([]*component.Component, 0, n)
+
+	for i := range n {
+		conf := &component.Config{
+			Name:     fmt.Sprintf("c%v", i),
+			Language: "go",
+			Targets: map[string]*target.Config{
+				"build": {
+					Parallel: true,
+					Steps:    []step.Config{{RunnerID: "test"}, {RunnerID: "test"}},
+				},
+				"test": {
+					Dependencies: []target.ID{"self::build"},
+					Steps:        []step.Config{{RunnerID: "test"}},
+				},
+			},
+		}
+
+		// Every component depends on the previous one.
+		if i != 0 {
+			conf.Targets["build"].Dependencies = []target.ID{
+				target.ID(fmt.Sprintf("c%v::build", i-1)),
+			}
+		}
+
+		require.NoError(t, conf.Init())
+		comp := component.NewComponent(conf, path.Join(root, conf.Name), "", "")
+		comps = append(comps, &comp)
+	}
+
+	return comps
+}
+
+func TestExecuteConcurrentNoChdir(t *testing.T) {
+	t.Parallel()
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	for _, parallel := range []bool{false, true} {
+		root := t.TempDir()
+
+		targets, prios, err := DefineExecutionOrder(generateDependentComps(t, root, 8), root)
+		require.NoError(t, err)
+
+		var count atomic.Int32
+		f := newTestFactory(t, "test", func(ctx runner.IContext) error {
+			count.Add(1)
+
+			wd, e := os.Getwd()
+			if e != nil {
+				return e
+			}
+
+			if wd != cwd {
+				return errors.New("working directory changed to '%v'", wd)
+			} else if ctx.Root() != root {
+				return errors.New("wrong root '%v'", ctx.Root())
+			} else if ctx.Component().Root() != path.Join(root, ctx.Component().Name()) {
+				return errors.New("wrong component root '%v'", ctx.Component().Root())
+			}
+
+			return nil
+		})
+
+		err = Execute(targets, prios, f, nil, nil, root, parallel,
+			WithForce(true), WithJobs(4))
+		require.NoError(t, err)
+		assert.EqualValues(t, 8*3, count.Load())
+
+		for id, n := range targets {
+			require.Len(t, n.Execution.Runners, len(n.Target.Steps), "target %v", id)
+			for i, r := range n.Execution.Runners {
+				assert.EqualValues(t, i, r.StepIdx, "target %v", id)
+				assert.EqualValues(t, ExecStatusSuccess, r.Status, "target %v", id)
+			}
+		}
+	}
+}
+
+func TestExecuteUnknownRunner(t *testing.T) {
+	t.Parallel()
+
+	for _, parallel := range []bool{false, true} {
+		root := t.TempDir()
+
+		targets, prios, err := DefineExecutionOrder(generateDependentComps(t, root, 2), root)
+		require.NoError(t, err)
+
+		var count atomic.Int32
+		f := newTestFactory(t, "other", func(runner.IContext) error {
+			count.Add(1)
+
+			return nil
+		})
+
+		err = Execute(targets, prios, f, nil, nil, root, parallel, WithForce(true))
+		require.ErrorContains(t, err, "failed to assemble all runners")
+		assert.Zero(t, count.Load())
+	}
+}
@@ -0,0 +1,255 @@
+package dag
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/common/set"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+// Shard denotes the part `Index` (1-based) of the selected targets
+// split into `Count` parts (see [WithShard]).
+type Shard struct {
+	Index int
+	Count int
+}
+
+// ParseShard parses a shard `k/n` where `1 <= k <= n`.
+func ParseShard(s string) (Shard, error) {
+	k, n, found := strings.Cut(s, "/")
+	if !found {
+		return Shard{}, errors.New("shard '%v' must have the format 'k/n'", s)
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(k))
+	if err != nil {
+		return Shard{}, errors.AddContext(err, "could not parse shard index in '%v'", s)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil {
+		return Shard{}, errors.AddContext(err, "could not parse shard count in '%v'", s)
+	}
+
+	shard := Shard{Index: index, Count: count}
+	if err := shard.Validate(); err != nil {
+		return Shard{}, err
+	}
+
+	return shard, nil
+}
+
+// Validate returns an error if the shard does not satisfy `1 <= k <= n`.
+func (s Shard) Validate() error {
+	if s.Count < 1 || s.Index < 1 || s.Index > s.Count {
+		return errors.New("shard '%v' must satisfy '1 <= k <= n'", s)
+	}
+
+	return nil
+}
+
+// String implements [fmt.Stringer].
+func (s Shard) String() string {
+	return fmt.Sprintf("%v/%v", s.Index, s.Count)
+}
+
+// LoadShardDurations loads the report in `file` (a JSON report or last run,
+// see [WithReport] and [WithLastRunFile]) and returns the recorded duration
+// of each target (the sum over all its runners which have run).
+func LoadShardDurations(file string) (map[target.ID]time.Duration, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.AddContext(err, "could not read durations report '%v'", file)
+	}
+
+	var r Report
+	err = json.Unmarshal(data, &r)
+	if err != nil {
+		return nil, errors.AddContext(err, "could not parse durations report '%v'", file)
+	}
+
+	durations := make(map[target.ID]time.Duration, len(r.Runners))
+	for i := range r.Runners {
+		if r.Runners[i].Duration <= 0 {
+			continue
+		}
+		durations[r.Runners[i].TargetID] += time.Duration(r.Runners[i].Duration * float64(time.Second))
+	}
+
+	return durations, nil
+}
+
+// selectShard returns the selection of shard `shard` over all connected
+// `nodes` and the selection `sel` (all leaf nodes if `nil`).
+func selectShard(
+	nodes TargetNodeMap,
+	sel *TargetSelection,
+	shard Shard,
+	durations map[target.ID]time.Duration,
+) *TargetSelection {
+	var selection []target.ID
+	if sel != nil {
+		selection = slices.Collect(sel.Keys())
+	} else {
+		for id, n := range nodes {
+			if len(n.Forward) == 0 {
+				selection = append(selection, id)
+			}
+		}
+	}
+
+	ids, estimate := shardSelection(nodes, selection, shard, durations)
+	log.Info("Selected shard.",
+		"shard", shard,
+		"targets", ids,
+		"selected", len(selection),
+		"estimate", estimate)
+
+	s := set.NewUnordered(ids...)
+
+	return &s
+}
+
+// shardSelection splits the selected targets `selection` over `shard.Count`
+// shards and returns the selected targets of shard `shard.Index`.
+// Each shard executes its targets together with all their dependencies, so the
+// cost of a target includes the cost of its dependencies not yet in the shard.
+// The cost of a target is its duration in `durations`, the mean recorded
+// duration if it has none, or uniform if nothing is recorded.
+// The targets are assigned greedily by descending cost to the shard
+// which keeps the longest shard the shortest, which is deterministic for each job.
+func shardSelection(
+	nodes TargetNodeMap,
+	selection []target.ID,
+	shard Shard,
+	durations map[target.ID]time.Duration,
+) (ids []target.ID, estimate time.Duration) {
+	cost := shardCosts(nodes, durations)
+
+	type shardItem struct {
+		id      target.ID
+		closure []target.ID
+		cost    time.Duration
+	}
+
+	items := make([]shardItem, 0, len(selection))
+	for _, id := range selection {
+		item := shardItem{id: id}
+
+		visited := set.NewUnordered[target.ID]()
+		visitNodesBFS([]*TargetNode{nodes[id]}, func(n *TargetNode) bool {
+			if visited.Exists(n.Target.ID) {
+				return false
+			}
+			visited.Insert(n.Target.ID)
+			item.closure = append(item.closure, n.Target.ID)
+			item.cost += cost(n.Target.ID)
+
+			return true
+		}, backwardDir)
+
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(a, b shardItem) int {
+		return cmp.Or(cmp.Compare(b.cost, a.cost), cmp.Compare(a.id, b.id))
+	})
+
+	type shardBin struct {
+		nodes    set.Unordered[target.ID]
+		selected []target.ID
+		cost     time.Duration
+	}
+
+	bins := make([]shardBin, shard.Count)
+	for i := range bins {
+		bins[i].nodes = set.NewUnordered[target.ID]()
+	}
+
+	var makespan time.Duration
+	for _, item := range items {
+		// Choose the shard with the smallest resulting makespan, then
+		// the least added cost (duplicated dependencies) and then the fewest targets.
+		best := -1
+		var bestCost, bestAdded time.Duration
+		for i := range bins {
+			var added time.Duration
+			for _, id := range item.closure {
+				if !bins[i].nodes.Exists(id) {
+					added += cost(id)
+				}
+			}
+
+			c := bins[i].cost + added
+			if best < 0 {
+				best, bestCost, bestAdded = i, c, added
+
+				continue
+			}
+
+			ms, bestMs := max(makespan, c), max(makespan, bestCost)
+			if cmp.Or(
+				cmp.Compare(ms, bestMs),
+				cmp.Compare(added, bestAdded),
+				cmp.Compare(len(bins[i].selected), len(bins[best].selected)),
+			) < 0 {
+				best, bestCost, bestAdded = i, c, added
+			}
+		}
+
+		b := &bins[best]
+		for _, id := range item.closure {
+			b.nodes.Insert(id)
+		}
+		b.selected = append(b.selected, item.id)
+		b.cost = bestCost
+		makespan = max(makespan, bestCost)
+	}
+
+	for i := range bins {
+		log.Debug("Shard.", "shard", Shard{Index: i + 1, Count: shard.Count},
+			"targets", len(bins[i].selected), "estimate", bins[i].cost)
+	}
+
+	b := bins[shard.Index-1]
+	slices.Sort(b.selected)
+
+	return b.selected, b.cost
+}
+
+// shardCosts returns the cost function for all targets in `nodes`.
+func shardCosts(
+	nodes TargetNodeMap,
+	durations map[target.ID]time.Duration,
+) func(target.ID) time.Duration {
+	var sum time.Duration
+	var count int
+	for id := range nodes {
+		if d, exists := durations[id]; exists {
+			sum += d
+			count++
+		}
+	}
+
+	fallback := time.Second
+	if count != 0 {
+		fallback = max(sum/time.Duration(count), time.Millisecond)
+	}
+
+	return func(id target.ID) time.Duration {
+		if d, exists := durations[id]; exists {
+			return d
+		}
+
+		return fallback
+	}
+}
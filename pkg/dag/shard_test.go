@@ -0,0 +1,103 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShard(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseShard("2/3")
+	require.NoError(t, err)
+	assert.Equal(t, Shard{Index: 2, Count: 3}, s)
+	assert.Equal(t, "2/3", s.String())
+
+	for _, invalid := range []string{"", "3", "0/3", "4/3", "1/0", "a/2", "1/b"} {
+		_, err = ParseShard(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	// Parsing and validating report the same error.
+	_, err = ParseShard("4/3")
+	require.EqualError(t, err, Shard{Index: 4, Count: 3}.Validate().Error())
+	require.NoError(t, s.Validate())
+}
+
+func TestShardSelection(t *testing.T) {
+	t.Parallel()
+
+	shardTargets := func(shard Shard, durations map[target.ID]time.Duration) []target.ID {
+		comps := generateChainComps(t)
+		targets, _, err := DefineExecutionOrder(
+			comps,
+			rootDir,
+			WithTargetsByStageFromComponents(comps, "build"),
+			WithShard(shard, durations),
+		)
+		require.NoError(t, err)
+
+		return slices.Sorted(maps.Keys(targets))
+	}
+
+	// The chain stays together with its dependencies and
+	// the unrelated target balances the other shard.
+	assert.Equal(t,
+		[]target.ID{"1::build", "2::build", "3::build"},
+		shardTargets(Shard{Index: 1, Count: 2}, nil))
+	assert.Equal(t,
+		[]target.ID{"4::build"},
+		shardTargets(Shard{Index: 2, Count: 2}, nil))
+
+	// More shards than needed are empty.
+	assert.Empty(t, shardTargets(Shard{Index: 3, Count: 3}, nil))
+
+	// With durations the longest target is assigned first.
+	durations := map[target.ID]time.Duration{
+		"1::build": time.Second,
+		"2::build": 10 * time.Minute,
+		"3::build": 10 * time.Minute,
+		"4::build": 30 * time.Minute,
+	}
+	assert.Equal(t,
+		[]target.ID{"4::build"},
+		shardTargets(Shard{Index: 1, Count: 2}, durations))
+	assert.Equal(t,
+		[]target.ID{"1::build", "2::build", "3::build"},
+		shardTargets(Shard{Index: 2, Count: 2}, durations))
+
+	_, _, err := DefineExecutionOrder(
+		generateChainComps(t), rootDir, WithShard(Shard{Index: 2, Count: 1}, nil))
+	require.ErrorContains(t, err, "must satisfy")
+}
+
+func TestLoadShardDurations(t *testing.T) {
+	t.Parallel()
+
+	statuses := append(testStatuses(),
+		&RunnerStatus{Status: ExecStatusSuccess, TargetID: "c::build", Duration: 2 * time.Second},
+		&RunnerStatus{Status: ExecStatusSuccess, TargetID: "c::build", Duration: time.Second},
+		&RunnerStatus{Status: ExecStatusNotRun, TargetID: "d::build"},
+	)
+
+	file := LastRunFile(t.TempDir())
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, WriteReport(f, statuses, ReportFormatJSON))
+	require.NoError(t, f.Close())
+
+	durations, err := LoadShardDurations(file)
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, durations["c::build"])
+	assert.NotContains(t, durations, target.ID("d::build"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/sdsc-ordes/quitsh/pkg/component/step"
 	"github.com/sdsc-ordes/quitsh/pkg/component/target"
@@ -17,6 +18,9 @@ import (
 const ExecStatusNotRun = 0
 const ExecStatusFailed = 1
 const ExecStatusSuccess = 2
+const ExecStatusUpToDate = 3
+const ExecStatusRestored = 4
+const ExecStatusCancelled = 5
 
 type (
 	ExecStatus int
@@ -29,6 +33,19 @@ type (
 		TargetID target.ID
 		StepIdx  step.Index
 		RunnerID runner.RegisterID
+
+		// The toolchain the runner runs in and if it is dispatched
+		// over the toolchain dispatcher.
+		Toolchain  string
+		Dispatched bool
+
+		// The number of attempts the runner took (see [step.Retry]).
+		Attempts int
+
+		// The start time and the duration of the runner (over all attempts).
+		// Not set if the runner did not run.
+		Start    time.Time
+		Duration time.Duration
 	}
 
 	RunnerStatuses []*RunnerStatus
@@ -39,6 +56,31 @@ type (
 	}
 )
 
+// String returns the name of the status.
+func (s ExecStatus) String() string {
+	switch s {
+	case ExecStatusNotRun:
+		return "not-run"
+	case ExecStatusFailed:
+		return "failed"
+	case ExecStatusSuccess:
+		return "success"
+	case ExecStatusUpToDate:
+		return "up-to-date"
+	case ExecStatusRestored:
+		return "restored"
+	case ExecStatusCancelled:
+		return "cancelled"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// IsSuccess returns `true` if the status counts as successful.
+func (s ExecStatus) IsSuccess() bool {
+	return s == ExecStatusSuccess || s == ExecStatusUpToDate || s == ExecStatusRestored
+}
+
 // AddStatus adds all runner statuses to the summary.
 func (s *Summary) AddStatus(r ...*RunnerStatus) {
 	for _, stat := range r {
@@ -54,6 +96,9 @@ func (s RunnerStatuses) log() {
 	const failedS = "❌"
 	const successS = "🌻"
 	const notRun = "🚫"
+	const upToDate = "💤"
+	const restored = "📦"
+	const cancelled = "🛑"
 	var statusS string
 
 	slices.SortFunc(s, func(a, b *RunnerStatus) int {
@@ -68,17 +113,28 @@ func (s RunnerStatuses) log() {
 			statusS = notRun
 		case ExecStatusFailed:
 			statusS = failedS
+		case ExecStatusUpToDate:
+			statusS = upToDate
+		case ExecStatusRestored:
+			statusS = restored
+		case ExecStatusCancelled:
+			statusS = cancelled
 		}
 
 		fmt.Fprintf(
 			&sb,
-			"  • %v: Component '%v', target id: '%v', step idx: '%v', runner id: '%v'\n",
+			"  • %v: Component '%v', target id: '%v', step idx: '%v', runner id: '%v'",
 			statusS,
 			stat.CompName,
 			stat.TargetID,
 			stat.StepIdx,
 			stat.RunnerID,
 		)
+
+		if stat.Attempts > 1 {
+			fmt.Fprintf(&sb, ", attempts: '%v'", stat.Attempts)
+		}
+		sb.WriteString("\n")
 	}
 
 	log.Info(sb.String())
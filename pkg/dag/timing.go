@@ -0,0 +1,161 @@
+package dag
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+// maxSlowestRunners is the number of slowest runners shown in the timings.
+const maxSlowestRunners = 5
+
+type (
+	// TargetTiming is the time span in which the runners of a target executed.
+	TargetTiming struct {
+		Node  *TargetNode
+		Start time.Time
+		End   time.Time
+	}
+
+	// Timings are the timings of an execution (see [NewTimings]).
+	Timings struct {
+		// The overall time span of all executed runners.
+		Start time.Time
+		End   time.Time
+
+		// The critical path in execution order: the chain of executed
+		// targets (over dependencies and weak ordering) which finished last.
+		CriticalPath []TargetTiming
+
+		// The slowest runners in descending duration.
+		Slowest RunnerStatuses
+	}
+)
+
+// Duration returns the duration of the target.
+func (t *TargetTiming) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// Duration returns the overall wall time.
+func (t *Timings) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// targetTiming returns the time span of all runners of the node
+// which executed.
+func (n *TargetNode) targetTiming() (t TargetTiming, ok bool) {
+	t.Node = n
+
+	for _, r := range n.Execution.Runners {
+		if r.Start.IsZero() {
+			continue
+		}
+
+		end := r.Start.Add(r.Duration)
+		if !ok || r.Start.Before(t.Start) {
+			t.Start = r.Start
+		}
+		if !ok || end.After(t.End) {
+			t.End = end
+		}
+		ok = true
+	}
+
+	return t, ok
+}
+
+// NewTimings computes the timings over all executed runners of `targets`.
+// The critical path is found by starting at the target which finished last and
+// following the predecessor (dependency or weak ordering) which finished last.
+func NewTimings(targets TargetNodeMap) (t Timings) {
+	timings := make(map[*TargetNode]TargetTiming, len(targets))
+	var last *TargetNode
+
+	for _, id := range slices.Sorted(maps.Keys(targets)) {
+		n := targets[id]
+
+		tt, ok := n.targetTiming()
+		if !ok {
+			continue
+		}
+		timings[n] = tt
+
+		if last == nil || tt.End.After(timings[last].End) {
+			last = n
+		}
+		if t.Start.IsZero() || tt.Start.Before(t.Start) {
+			t.Start = tt.Start
+		}
+
+		for _, r := range n.Execution.Runners {
+			if !r.Start.IsZero() {
+				t.Slowest = append(t.Slowest, r)
+			}
+		}
+	}
+
+	if last == nil {
+		return t
+	}
+	t.End = timings[last].End
+
+	for n := last; n != nil; {
+		t.CriticalPath = append(t.CriticalPath, timings[n])
+
+		var pred *TargetNode
+		for _, p := range slices.Concat(n.Backward, n.After) {
+			pt, ok := timings[p]
+			if ok && (pred == nil || pt.End.After(timings[pred].End)) {
+				pred = p
+			}
+		}
+		n = pred
+	}
+	slices.Reverse(t.CriticalPath)
+
+	slices.SortStableFunc(t.Slowest, func(a, b *RunnerStatus) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	t.Slowest = t.Slowest[:min(len(t.Slowest), maxSlowestRunners)]
+
+	return t
+}
+
+// Format formats the timings human-readable.
+func (t *Timings) Format() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Timings: total wall time '%v'\n", t.Duration().Round(time.Millisecond))
+
+	sb.WriteString("Critical path:\n")
+	for i := range t.CriticalPath {
+		tt := &t.CriticalPath[i]
+		fmt.Fprintf(&sb, "  • '%v': '%v' (started after '%v')\n",
+			tt.Node.Target.ID,
+			tt.Duration().Round(time.Millisecond),
+			tt.Start.Sub(t.Start).Round(time.Millisecond))
+	}
+
+	sb.WriteString("Slowest steps:\n")
+	for _, r := range t.Slowest {
+		fmt.Fprintf(&sb, "  • '%v': target id: '%v', step idx: '%v', runner id: '%v'\n",
+			r.Duration.Round(time.Millisecond), r.TargetID, r.StepIdx, r.RunnerID)
+	}
+
+	return sb.String()
+}
+
+// logTimings logs the timings of all executed runners of `targets`.
+func logTimings(targets TargetNodeMap) {
+	t := NewTimings(targets)
+	if len(t.CriticalPath) == 0 {
+		return
+	}
+
+	log.Info(t.Format())
+}
@@ -0,0 +1,74 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimings(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	nodes, _, err := DefineExecutionOrder(generateFingerprintComps(t, root), root)
+	require.NoError(t, err)
+	a := nodes["a::build"]
+	b := nodes["b::build"]
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	*a.Execution.AddRunnerStatus() = RunnerStatus{
+		TargetID: "a::build", Start: start, Duration: 2 * time.Second,
+	}
+	*b.Execution.AddRunnerStatus() = RunnerStatus{
+		TargetID: "b::build", Start: start.Add(2 * time.Second), Duration: 3 * time.Second,
+	}
+	// Not executed.
+	b.Execution.AddRunnerStatus()
+
+	tm := NewTimings(nodes)
+	assert.Equal(t, 5*time.Second, tm.Duration())
+	require.Len(t, tm.CriticalPath, 2)
+	assert.Equal(t, a, tm.CriticalPath[0].Node)
+	assert.Equal(t, b, tm.CriticalPath[1].Node)
+	assert.Equal(t, 3*time.Second, tm.CriticalPath[1].Duration())
+
+	require.Len(t, tm.Slowest, 2)
+	assert.EqualValues(t, "b::build", tm.Slowest[0].TargetID)
+	assert.Contains(t, tm.Format(), "'a::build': '2s'")
+}
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	statuses := RunnerStatuses{
+		{TargetID: "a::build", Start: start, Duration: 2 * time.Second},
+		{TargetID: "b::build", Start: start.Add(time.Second), Duration: 2 * time.Second},
+		{TargetID: "c::build", Start: start.Add(2 * time.Second), Duration: time.Second},
+		{TargetID: "d::build"}, // Not executed.
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTrace(&buf, statuses))
+
+	var trace traceFile
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+
+	lanes := map[string]int{}
+	for _, e := range trace.TraceEvents {
+		if e.Phase == "X" {
+			lanes[e.Name] = e.TID
+		}
+	}
+
+	// `a` and `b` overlap, `c` reuses the lane of `a`.
+	assert.Len(t, lanes, 3)
+	assert.NotEqual(t, lanes["a::build [0]"], lanes["b::build [0]"])
+	assert.Equal(t, lanes["a::build [0]"], lanes["c::build [0]"])
+}
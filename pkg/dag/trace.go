@@ -0,0 +1,130 @@
+package dag
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+)
+
+type (
+	// traceEvent is an event in the Chrome trace event format.
+	traceEvent struct {
+		Name  string         `json:"name"`
+		Cat   string         `json:"cat,omitempty"`
+		Phase string         `json:"ph"`
+		TS    int64          `json:"ts"`
+		Dur   int64          `json:"dur,omitempty"`
+		PID   int            `json:"pid"`
+		TID   int            `json:"tid"`
+		Args  map[string]any `json:"args,omitempty"`
+	}
+
+	traceFile struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}
+)
+
+// WriteTrace writes all executed runners in `statuses` in the Chrome trace event
+// format (viewable in Perfetto or `chrome://tracing`) to `w`.
+// Each concurrently executing runner gets its own lane (worker).
+func WriteTrace(w io.Writer, statuses RunnerStatuses) error {
+	var ran RunnerStatuses
+	for _, r := range statuses {
+		if !r.Start.IsZero() {
+			ran = append(ran, r)
+		}
+	}
+
+	slices.SortStableFunc(ran, func(a, b *RunnerStatus) int {
+		return cmp.Or(
+			a.Start.Compare(b.Start),
+			cmp.Compare(a.TargetID, b.TargetID))
+	})
+
+	var origin time.Time
+	if len(ran) != 0 {
+		origin = ran[0].Start
+	}
+
+	// Assign each runner the first free lane.
+	var laneEnds []time.Time
+	events := []traceEvent{}
+
+	for _, r := range ran {
+		lane := slices.IndexFunc(laneEnds, func(end time.Time) bool {
+			return !end.After(r.Start)
+		})
+		if lane < 0 {
+			lane = len(laneEnds)
+			laneEnds = append(laneEnds, time.Time{})
+			events = append(events, traceEvent{
+				Name:  "thread_name",
+				Phase: "M",
+				PID:   1,
+				TID:   lane,
+				Args:  map[string]any{"name": fmt.Sprintf("worker-%v", lane)},
+			})
+		}
+		laneEnds[lane] = r.Start.Add(r.Duration)
+
+		cat := "runner"
+		if r.Dispatched {
+			cat = "dispatch"
+		}
+
+		events = append(events, traceEvent{
+			Name:  fmt.Sprintf("%v [%v]", r.TargetID, r.StepIdx),
+			Cat:   cat,
+			Phase: "X",
+			TS:    r.Start.Sub(origin).Microseconds(),
+			Dur:   max(r.Duration.Microseconds(), 1),
+			PID:   1,
+			TID:   lane,
+			Args: map[string]any{
+				"component":  r.CompName,
+				"target":     r.TargetID,
+				"step":       r.StepIdx,
+				"runner":     r.RunnerID,
+				"toolchain":  r.Toolchain,
+				"dispatched": r.Dispatched,
+				"status":     r.Status.String(),
+				"attempts":   r.Attempts,
+			},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+
+	return enc.Encode(traceFile{TraceEvents: events, DisplayTimeUnit: "ms"})
+}
+
+// WriteTraceFile writes the trace over all runners of all `targets` to
+// the file `path` (see [WriteTrace]).
+func WriteTraceFile(path string, targets TargetNodeMap) error {
+	var statuses RunnerStatuses
+	for _, n := range targets {
+		statuses = append(statuses, n.Execution.Runners...)
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), fs.DefaultPermissionsDir)
+	if err != nil {
+		return errors.AddContext(err, "could not create directory for trace '%v'", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.AddContext(err, "could not create trace '%v'", path)
+	}
+	defer f.Close()
+
+	return errors.AddContext(WriteTrace(f, statuses), "could not write trace '%v'", path)
+}
@@ -0,0 +1,101 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+)
+
+type (
+	// Explanation explains why a target is considered changed (see [Explain]).
+	Explanation struct {
+		ID      target.ID `json:"id"`
+		Changed bool      `json:"changed"`
+
+		// If the target changed by its own inputs and the reason.
+		// The reason is `nil` if no changed paths were given
+		// (all targets are changed).
+		ChangedByInputs bool          `json:"changedByInputs"`
+		Reason          *ChangeReason `json:"reason,omitempty"`
+
+		// If the target changed by a dependency and the chain of upstream targets
+		// which carried the change, starting at the direct dependency and
+		// ending at the target which changed by its own inputs.
+		ChangedByDependency bool              `json:"changedByDependency"`
+		Chain               []ExplanationLink `json:"chain,omitempty"`
+	}
+
+	// ExplanationLink is an upstream target in the change chain of an [Explanation].
+	ExplanationLink struct {
+		ID     target.ID     `json:"id"`
+		Reason *ChangeReason `json:"reason,omitempty"`
+	}
+)
+
+// Explain explains why the target node `n` is considered changed.
+// The node must have been resolved with [DefineExecutionOrder].
+func Explain(n *TargetNode) Explanation {
+	e := Explanation{
+		ID:                  n.Target.ID,
+		Changed:             n.Inputs.IsChanged(),
+		ChangedByInputs:     n.Inputs.Changed,
+		Reason:              n.Inputs.Reason,
+		ChangedByDependency: n.Inputs.ChangedByDependency,
+	}
+
+	for _, c := range n.ChangeChain() {
+		e.Chain = append(e.Chain, ExplanationLink{ID: c.Target.ID, Reason: c.Inputs.Reason})
+	}
+
+	return e
+}
+
+// Format formats the explanation human-readable.
+func (e *Explanation) Format() string {
+	var sb strings.Builder
+
+	if !e.Changed {
+		fmt.Fprintf(&sb,
+			"Target '%v' is not changed: "+
+				"no input matched the changed paths and no dependency changed.\n", e.ID)
+
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "Target '%v' is changed:\n", e.ID)
+
+	if e.ChangedByInputs {
+		sb.WriteString("• By its own inputs: ")
+		sb.WriteString(formatReason(e.Reason))
+		sb.WriteString("\n")
+	}
+
+	if e.ChangedByDependency {
+		sb.WriteString("• By a dependency over the chain:\n")
+		fmt.Fprintf(&sb, "  - '%v'\n", e.ID)
+
+		for _, l := range e.Chain {
+			fmt.Fprintf(&sb, "  - '%v'\n", l.ID)
+		}
+
+		if len(e.Chain) != 0 {
+			fmt.Fprintf(&sb, "  where '%v' changed by its own inputs: %v\n",
+				e.Chain[len(e.Chain)-1].ID, formatReason(e.Chain[len(e.Chain)-1].Reason))
+		}
+	}
+
+	return sb.String()
+}
+
+func formatReason(r *ChangeReason) string {
+	switch {
+	case r == nil:
+		return "no changed paths given (all targets are changed)"
+	case r.Pattern == "":
+		return fmt.Sprintf("input '%v' contains path '%v'", r.InputID, r.Path)
+	default:
+		return fmt.Sprintf("input '%v' matched path '%v' with pattern '%v'",
+			r.InputID, r.Path, r.Pattern)
+	}
+}
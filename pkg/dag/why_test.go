@@ -0,0 +1,64 @@
+//go:build test && (test_small || test_all)
+
+package dag
+
+import (
+	"testing"
+
+	"github.com/sdsc-ordes/quitsh/pkg/component/input"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	comps, paths := generate3Comps(t)
+	targets, _, e := DefineExecutionOrder(comps, rootDir, WithInputChanges(paths))
+	require.NoError(t, e)
+
+	ex := Explain(targets["2::build2"])
+	assert.True(t, ex.Changed)
+	assert.True(t, ex.ChangedByInputs)
+	assert.False(t, ex.ChangedByDependency)
+	require.NotNil(t, ex.Reason)
+	assert.Equal(t, input.ID("2::in2"), ex.Reason.InputID)
+	assert.Equal(t, "^components/.*/!-file-must-.*$", ex.Reason.Pattern)
+	assert.Equal(t, "components/2/!-file-must-match", ex.Reason.Path)
+	assert.Contains(t, ex.Format(), "pattern '^components/.*/!-file-must-.*$'")
+
+	ex = Explain(targets["3::build3"])
+	assert.True(t, ex.Changed)
+	assert.False(t, ex.ChangedByInputs)
+	assert.True(t, ex.ChangedByDependency)
+	require.Len(t, ex.Chain, 1)
+	assert.Equal(t, target.ID("2::build2"), ex.Chain[0].ID)
+	require.NotNil(t, ex.Chain[0].Reason)
+	assert.Contains(t, ex.Format(), "where '2::build2' changed by its own inputs")
+
+	ex = Explanation{ID: "1::build1"}
+	assert.Contains(t, ex.Format(), "is not changed")
+}
+
+func TestExplainChain(t *testing.T) {
+	t.Parallel()
+
+	comps := generateChainComps(t)
+	targets, _, e := DefineExecutionOrder(
+		comps,
+		rootDir,
+		WithInputChanges([]string{"/repo/components/1/file"}),
+	)
+	require.NoError(t, e)
+
+	ex := Explain(targets["3::build"])
+	require.Len(t, ex.Chain, 2)
+	assert.Equal(t, target.ID("2::build"), ex.Chain[0].ID)
+	assert.Equal(t, target.ID("1::build"), ex.Chain[1].ID)
+	require.NotNil(t, ex.Chain[1].Reason)
+	assert.Equal(t, input.ID("1"), ex.Chain[1].Reason.InputID)
+	assert.Empty(t, ex.Chain[1].Reason.Pattern)
+	assert.Equal(t, "/repo/components/1/file", ex.Chain[1].Reason.Path)
+}
@@ -70,9 +70,10 @@ type (
 	}
 
 	Waiter struct {
-		c   *CmdContext
-		cmd *exec.Cmd
-		buf *bytes.Buffer
+		c    *CmdContext
+		cmd  *exec.Cmd
+		wait func() error
+		buf  *bytes.Buffer
 	}
 )
 
@@ -146,7 +147,7 @@ func (c *CmdContext) GetWithEC(handleExit ExitCodeHandler, args ...string) (stri
 		return "", err
 	}
 
-	cmd := exec.CommandContext(c.getContext(), baseCmd, args...)
+	cmd := c.newCommand(baseCmd, args)
 	cmd.Dir = c.cwd
 	cmd.Env = c.env
 
@@ -155,7 +156,7 @@ func (c *CmdContext) GetWithEC(handleExit ExitCodeHandler, args ...string) (stri
 	if c.logCommand {
 		logCommand(c, cmd)
 	}
-	stdout, err := cmd.Output()
+	stdout, err := outputCommand(cmd)
 	err = handleExitCode(
 		c, cmd, err, buf,
 		c.enableEnvPrint || EnableEnvPrint, handleExit,
@@ -176,7 +177,7 @@ func (c *CmdContext) GetStdErrWithEC(
 		return "", "", err
 	}
 
-	cmd := exec.CommandContext(c.getContext(), baseCmd, args...)
+	cmd := c.newCommand(baseCmd, args)
 	cmd.Dir = c.cwd
 	cmd.Env = c.env
 
@@ -186,7 +187,7 @@ func (c *CmdContext) GetStdErrWithEC(
 	if c.logCommand {
 		logCommand(c, cmd)
 	}
-	stdout, err := cmd.Output()
+	stdout, err := outputCommand(cmd)
 
 	b := buf
 	if !c.captureError {
@@ -223,14 +224,14 @@ func (c *CmdContext) GetCombinedWithEC(handleExit ExitCodeHandler, args ...strin
 		return "", err
 	}
 
-	cmd := exec.CommandContext(c.getContext(), baseCmd, args...)
+	cmd := c.newCommand(baseCmd, args)
 	cmd.Dir = c.cwd
 	cmd.Env = c.env
 
 	if c.logCommand {
 		logCommand(c, cmd)
 	}
-	stdout, err := cmd.CombinedOutput()
+	stdout, err := combinedOutputCommand(cmd)
 	err = handleExitCode(
 		c, cmd, err, nil,
 		c.enableEnvPrint || EnableEnvPrint, handleExit,
@@ -254,12 +255,12 @@ func (c *CmdContext) CheckWithEC(handleExit ExitCodeHandler, args ...string) err
 		return err
 	}
 
-	cmd := exec.CommandContext(c.getContext(), baseCmd, args...)
+	cmd := c.newCommand(baseCmd, args)
 	cmd.Dir = c.cwd
 	cmd.Env = c.env
 
 	if c.pipeOutput {
-		cmd.Stdout = os.Stdout
+		cmd.Stdout = c.stdout()
 	}
 
 	buf := setupCapture(c, cmd, false)
@@ -267,7 +268,7 @@ func (c *CmdContext) CheckWithEC(handleExit ExitCodeHandler, args ...string) err
 	if c.logCommand {
 		logCommand(c, cmd)
 	}
-	err = cmd.Run()
+	err = runCommand(cmd)
 
 	return handleExitCode(c, cmd, err, buf, c.enableEnvPrint || EnableEnvPrint, handleExit)
 }
@@ -287,7 +288,7 @@ func (c *CmdContext) CheckPipe(args ...string) (waiter Waiter, pipe io.ReadClose
 		return
 	}
 
-	cmd := exec.CommandContext(c.getContext(), baseCmd, args...)
+	cmd := c.newCommand(baseCmd, args)
 	cmd.Dir = c.cwd
 	cmd.Env = c.env
 
@@ -298,7 +299,7 @@ func (c *CmdContext) CheckPipe(args ...string) (waiter Waiter, pipe io.ReadClose
 		return
 	}
 
-	err = cmd.Start()
+	waiter.wait, err = startCommand(cmd)
 	if err != nil {
 		return
 	}
@@ -311,7 +312,7 @@ func (c *CmdContext) CheckPipe(args ...string) (waiter Waiter, pipe io.ReadClose
 
 // Wait waits till the cmd has finished.
 func (w Waiter) Wait() error {
-	err := w.cmd.Wait()
+	err := w.wait()
 
 	return handleExitCode(w.c, w.cmd, err, w.buf, w.c.enableEnvPrint || EnableEnvPrint, nil)
 }
@@ -365,6 +366,83 @@ func formatError(cmd *exec.Cmd, stderr string, exitCode int, enableEnvPrint bool
 	)
 }
 
+// newCommand creates the command `baseCmd` with `args` on the context.
+// When the context is cancelled, the whole process tree of the command is killed.
+func (c *CmdContext) newCommand(baseCmd string, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(c.getContext(), baseCmd, args...)
+	setKillProcessGroup(cmd)
+
+	return cmd
+}
+
+// startCommand starts `cmd` like [exec.Cmd.Start].
+// The returned `wait` must be used instead of [exec.Cmd.Wait].
+func startCommand(cmd *exec.Cmd) (wait func() error, err error) {
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	untrack := trackProcessGroup(cmd)
+
+	return func() error {
+		defer untrack()
+
+		return cmd.Wait()
+	}, nil
+}
+
+// runCommand runs `cmd` like [exec.Cmd.Run].
+func runCommand(cmd *exec.Cmd) error {
+	wait, err := startCommand(cmd)
+	if err != nil {
+		return err
+	}
+
+	return wait()
+}
+
+// outputCommand runs `cmd` and returns its stdout like [exec.Cmd.Output].
+func outputCommand(cmd *exec.Cmd) ([]byte, error) {
+	if cmd.Stdout != nil {
+		return nil, cerr.New("stdout already set")
+	}
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	var stderr *bytes.Buffer
+	if cmd.Stderr == nil {
+		stderr = &bytes.Buffer{}
+		cmd.Stderr = stderr
+	}
+
+	err := runCommand(cmd)
+
+	var exitErr *exec.ExitError
+	if stderr != nil && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+
+	return stdout.Bytes(), err
+}
+
+// combinedOutputCommand runs `cmd` and returns its stdout and stderr
+// like [exec.Cmd.CombinedOutput].
+func combinedOutputCommand(cmd *exec.Cmd) ([]byte, error) {
+	if cmd.Stdout != nil || cmd.Stderr != nil {
+		return nil, cerr.New("stdout or stderr already set")
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := runCommand(cmd)
+
+	return out.Bytes(), err
+}
+
 func (c *CmdContext) getContext() context.Context {
 	if c.ctx != nil {
 		return c.ctx
@@ -413,12 +491,12 @@ func setupCapture(c *CmdContext, cmd *exec.Cmd, forceCapture bool) (buf *bytes.B
 	if c.captureError || forceCapture {
 		buf = bytes.NewBuffer(nil)
 		if c.pipeOutput {
-			cmd.Stderr = io.MultiWriter(os.Stderr, buf)
+			cmd.Stderr = io.MultiWriter(c.stderr(), buf)
 		} else {
 			cmd.Stderr = buf
 		}
 	} else if c.pipeOutput {
-		cmd.Stderr = os.Stderr
+		cmd.Stderr = c.stderr()
 	}
 
 	return
@@ -3,6 +3,7 @@
 package exec
 
 import (
+	"context"
 	"io"
 	"os"
 	"os/exec"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sdsc-ordes/quitsh/pkg/errors"
 	"github.com/sdsc-ordes/quitsh/pkg/exec/lookpath"
@@ -36,6 +38,36 @@ func TestCommandCtxNormal(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCommandCtxTimeoutKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	c := NewCmdCtxBuilder().Context(ctx).Build()
+
+	// The background child holds the stdout open, such that this only returns
+	// before the wait delay if the whole process group is killed.
+	start := time.Now()
+	_, err := c.Get("sh", "-c", "sleep 30 & wait")
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), killWaitDelay)
+}
+
+func TestCommandCtxCancelKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	// The output is not a file, such that the background child holds
+	// the pipe open until the whole process group is killed.
+	var out strings.Builder
+	c := NewCmdCtxBuilder().Context(WithOutput(ctx, &out, &out)).Build()
+
+	start := time.Now()
+	err := c.Check("sh", "-c", "sleep 30 & echo started && wait")
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), killWaitDelay)
+	assert.Equal(t, "started\n", out.String())
+}
+
 func TestCommandCtxStdErr(t *testing.T) {
 	ctx := NewCommandCtx(".")
 
@@ -61,6 +93,23 @@ func TestCommandCtxStdIn(t *testing.T) {
 	assert.Empty(t, stdout)
 }
 
+func TestCommandCtxWithOutput(t *testing.T) {
+	var stdout, stderr strings.Builder
+	ctx := WithOutput(t.Context(), &stdout, &stderr)
+
+	c := NewCmdCtxBuilder().Context(ctx).Build()
+	err := c.Check("sh", "-c", "echo 'Banana' && echo 'Monkey' >&2")
+	require.NoError(t, err)
+	assert.Equal(t, "Banana\n", stdout.String())
+	assert.Equal(t, "Monkey\n", stderr.String())
+
+	// The stderr is captured in addition.
+	c = NewCmdCtxBuilder().Context(ctx).EnableCaptureError().Build()
+	err = c.Check("sh", "-c", "echo 'Apple' >&2 && false")
+	require.ErrorContains(t, err, "Apple")
+	assert.Equal(t, "Monkey\nApple\n", stderr.String())
+}
+
 func TestCommandCtxBuilderAddArgs(t *testing.T) {
 	ctx := NewCmdCtxBuilder().BaseCmd("ls").
 		BaseArgs("-a").
@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"errors"
 	"os/exec"
 )
 
@@ -35,3 +36,18 @@ func NewCmdError(
 		stderr:   stderr,
 	}
 }
+
+// GetExitCode returns the exit code of the first [CmdError] wrapped in `err`.
+func GetExitCode(err error) (int, bool) {
+	var e CmdError
+	if errors.As(err, &e) {
+		return e.exitCode, true
+	}
+
+	var eP *CmdError
+	if errors.As(err, &eP) && eP != nil {
+		return eP.exitCode, true
+	}
+
+	return 0, false
+}
@@ -430,6 +430,17 @@ func (gitx *Context) IsRefReachable(fromRef string, ref string) (reachable bool,
 	return
 }
 
+// MergeBase returns the best common ancestor commit of `refA` and `refB`.
+func (gitx *Context) MergeBase(refA string, refB string) (string, error) {
+	sha1, err := gitx.Get("merge-base", refA, refB)
+	if err != nil {
+		return "", errors.AddContext(err,
+			"could not determine merge-base of '%s' and '%s'", refA, refB)
+	}
+
+	return sha1, nil
+}
+
 type FetchUntilOpts struct {
 	Factor     int
 	StartDepth int
@@ -303,6 +303,21 @@ func TestIsRefReachable(t *testing.T) {
 	assert.False(t, reachable)
 }
 
+func TestMergeBase(t *testing.T) {
+	t.Parallel()
+	gitx := setupGitRepo(t)
+
+	expected, err := gitx.Get("rev-parse", "HEAD~2")
+	require.NoError(t, err)
+
+	base, err := gitx.MergeBase("HEAD~2", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, expected, base)
+
+	_, err = gitx.MergeBase("does-not-exist", "HEAD")
+	require.Error(t, err)
+}
+
 func TestFetchUntil(t *testing.T) {
 	t.Parallel()
 	gitx := setupGitRepo(t)
@@ -0,0 +1,42 @@
+package exec
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+type (
+	outputKey struct{}
+
+	output struct {
+		stdout io.Writer
+		stderr io.Writer
+	}
+)
+
+// WithOutput returns a context derived from `ctx` which routes the piped
+// stdout and stderr of all commands executed with it (see [CmdContextBuilder.Context])
+// to `stdout` and `stderr` instead of [os.Stdout] and [os.Stderr].
+// The writers must be safe for concurrent use if commands run concurrently.
+func WithOutput(ctx context.Context, stdout io.Writer, stderr io.Writer) context.Context {
+	return context.WithValue(ctx, outputKey{}, output{stdout: stdout, stderr: stderr})
+}
+
+// stdout returns the writer where the stdout of commands is piped to.
+func (c *CmdContext) stdout() io.Writer {
+	if o, ok := c.getContext().Value(outputKey{}).(output); ok && o.stdout != nil {
+		return o.stdout
+	}
+
+	return os.Stdout
+}
+
+// stderr returns the writer where the stderr of commands is piped to.
+func (c *CmdContext) stderr() io.Writer {
+	if o, ok := c.getContext().Value(outputKey{}).(output); ok && o.stderr != nil {
+		return o.stderr
+	}
+
+	return os.Stderr
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package exec
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// killWaitDelay is the time to wait for the output of a killed command.
+const killWaitDelay = 5 * time.Second
+
+//nolint:gochecknoglobals // The signal forwarding is process-wide.
+var processGroups = struct {
+	pids    map[int]struct{}
+	signals chan os.Signal
+	mutex   sync.Mutex
+}{pids: make(map[int]struct{})}
+
+// setKillProcessGroup starts the command `cmd` in its own process group
+// and kills the whole group on context cancellation (e.g. timeouts or
+// an aborted run), such that no child processes (e.g. from `nix develop`
+// or shells) are left behind and keep the output open.
+// Since the group does not receive the terminal interrupts (`Ctrl+C`)
+// anymore, they are forwarded while the command runs (see [trackProcessGroup]).
+func setKillProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = killWaitDelay
+}
+
+// trackProcessGroup forwards interrupts to the process group of the
+// started command `cmd` until `untrack` is called after waiting on it.
+func trackProcessGroup(cmd *exec.Cmd) (untrack func()) {
+	pid := cmd.Process.Pid
+
+	processGroups.mutex.Lock()
+	defer processGroups.mutex.Unlock()
+
+	if len(processGroups.pids) == 0 {
+		processGroups.signals = make(chan os.Signal, 1)
+		signal.Notify(processGroups.signals, os.Interrupt)
+		go forwardSignals(processGroups.signals)
+	}
+	processGroups.pids[pid] = struct{}{}
+
+	return func() {
+		processGroups.mutex.Lock()
+		defer processGroups.mutex.Unlock()
+
+		delete(processGroups.pids, pid)
+		if len(processGroups.pids) == 0 {
+			signal.Stop(processGroups.signals)
+			close(processGroups.signals)
+		}
+	}
+}
+
+func forwardSignals(signals <-chan os.Signal) {
+	for sig := range signals {
+		processGroups.mutex.Lock()
+		for pid := range processGroups.pids {
+			_ = syscall.Kill(-pid, sig.(syscall.Signal)) //nolint:forcetypeassert // Always on unix.
+		}
+		processGroups.mutex.Unlock()
+	}
+}
@@ -32,6 +32,10 @@ const (
 
 	OutCIDir = "ci"
 
+	OutFingerprintDir = "fingerprint"
+
+	OutLogsDir = "logs"
+
 	DocsDir   = "docs"
 	ImagesDir = "images"
 )
@@ -0,0 +1,107 @@
+//go:build linux
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path"
+	"unsafe"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB
+
+// inotifyWatcher detects changes over inotify.
+// Since inotify is not recursive, every directory gets its own watch
+// and new directories are added when created.
+type inotifyWatcher struct {
+	// Note: Do not use `file.Fd()` which sets the file to blocking mode.
+	fd       int
+	file     *os.File
+	excluded func(string) bool
+
+	// Watched directories by watch descriptor.
+	dirs map[int]string
+}
+
+func newNativeWatcher(dirs []string, excluded func(string) bool) (watcher, error) {
+	// Non-blocking, such that the Go runtime poller is used and
+	// closing the file unblocks a pending read.
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, errors.AddContext(err, "could not initialize inotify")
+	}
+
+	w := &inotifyWatcher{
+		fd:       fd,
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		excluded: excluded,
+		dirs:     make(map[int]string),
+	}
+
+	walkDirs(dirs, excluded, w.add)
+
+	return w, nil
+}
+
+func (w *inotifyWatcher) add(dir string) {
+	wd, err := unix.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		log.WarnE(err, "Could not watch directory.", "dir", dir)
+
+		return
+	}
+
+	w.dirs[wd] = dir
+}
+
+func (w *inotifyWatcher) run(ctx context.Context, changes chan<- string) error {
+	go func() {
+		<-ctx.Done()
+		_ = w.file.Close()
+	}()
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1)) //nolint:mnd
+
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return errors.AddContext(err, "could not read inotify events")
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset])) //nolint:gosec // kernel layout
+			nameBytes := buf[offset+unix.SizeofInotifyEvent : offset+unix.SizeofInotifyEvent+int(event.Len)]
+			offset += unix.SizeofInotifyEvent + int(event.Len)
+
+			dir, exists := w.dirs[int(event.Wd)]
+			if !exists || event.Len == 0 {
+				continue
+			}
+
+			p := path.Join(dir, unix.ByteSliceToString(nameBytes))
+
+			if event.Mask&unix.IN_ISDIR != 0 {
+				if event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 && !w.excluded(p) {
+					walkDirs([]string{p}, w.excluded, w.add)
+				}
+
+				continue
+			}
+
+			if !send(ctx, changes, p) {
+				return ctx.Err()
+			}
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package watch
+
+// newNativeWatcher falls back to polling on platforms without inotify.
+func newNativeWatcher(dirs []string, excluded func(string) bool) (watcher, error) {
+	return newPoller(dirs, DefaultPollInterval, excluded), nil
+}
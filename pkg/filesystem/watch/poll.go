@@ -0,0 +1,102 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path"
+	"time"
+)
+
+type (
+	fileState struct {
+		modTime time.Time
+		size    int64
+	}
+
+	// poller detects changes by scanning all directories periodically.
+	poller struct {
+		dirs     []string
+		interval time.Duration
+		excluded func(string) bool
+
+		// The state of the last scan.
+		last map[string]fileState
+	}
+)
+
+func newPoller(dirs []string, interval time.Duration, excluded func(string) bool) *poller {
+	p := &poller{dirs: dirs, interval: interval, excluded: excluded}
+	// Scan directly such that changes after setup are never missed.
+	p.last = p.scan()
+
+	return p
+}
+
+func (p *poller) run(ctx context.Context, changes chan<- string) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		curr := p.scan()
+
+		for f, s := range curr {
+			if l, exists := p.last[f]; !exists || l != s {
+				if !send(ctx, changes, f) {
+					return ctx.Err()
+				}
+			}
+		}
+
+		for f := range p.last {
+			if _, exists := curr[f]; !exists {
+				if !send(ctx, changes, f) {
+					return ctx.Err()
+				}
+			}
+		}
+
+		p.last = curr
+	}
+}
+
+// scan returns the state of all files in all watched directories.
+func (p *poller) scan() map[string]fileState {
+	files := make(map[string]fileState)
+
+	walkDirs(p.dirs, p.excluded, func(dir string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+
+			files[path.Join(dir, e.Name())] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	})
+
+	return files
+}
+
+func send(ctx context.Context, changes chan<- string, p string) bool {
+	select {
+	case changes <- p:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,200 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sdsc-ordes/quitsh/pkg/errors"
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+	"github.com/sdsc-ordes/quitsh/pkg/log"
+)
+
+const (
+	// DefaultDebounce is the default time to wait for further changes
+	// before reporting them.
+	DefaultDebounce = 300 * time.Millisecond
+
+	// DefaultPollInterval is the default interval between two scans
+	// when polling.
+	DefaultPollInterval = time.Second
+)
+
+type (
+	Option func(*options) error
+
+	options struct {
+		debounce time.Duration
+
+		// If polling is used instead of the native file system events.
+		poll         bool
+		pollInterval time.Duration
+
+		// Exclude directories (and everything below) from being watched.
+		exclude func(dir string) bool
+	}
+
+	// watcher is the source of raw changed paths.
+	watcher interface {
+		// run sends changed absolute paths to `changes` until `ctx` is done.
+		run(ctx context.Context, changes chan<- string) error
+	}
+)
+
+// Watch watches all directories `dirs` recursively for changed files
+// and sends the changed absolute paths in debounced batches (sorted, unique)
+// to the returned channel. The channel is closed when `ctx` is done.
+// By default the native file system events are used (inotify on Linux) and
+// polling otherwise (see [WithPolling]).
+// Directories are excluded with [WithExclude].
+func Watch(ctx context.Context, dirs []string, opts ...Option) (<-chan []string, error) {
+	o := options{debounce: DefaultDebounce, pollInterval: DefaultPollInterval}
+	for _, opt := range opts {
+		if e := opt(&o); e != nil {
+			return nil, e
+		}
+	}
+
+	dirs = slices.Clone(dirs)
+	for i := range dirs {
+		dirs[i] = fs.MakeAbsolute(dirs[i])
+	}
+	slices.Sort(dirs)
+	dirs = slices.Compact(dirs)
+
+	var w watcher
+	var err error
+	if o.poll {
+		w = newPoller(dirs, o.pollInterval, o.isExcluded)
+	} else {
+		w, err = newNativeWatcher(dirs, o.isExcluded)
+		if err != nil {
+			return nil, errors.AddContext(err, "could not setup file watcher")
+		}
+	}
+
+	changes := make(chan string)
+	batches := make(chan []string)
+
+	go func() {
+		defer close(changes)
+
+		e := w.run(ctx, changes)
+		if e != nil && ctx.Err() == nil {
+			log.ErrorE(e, "File watcher failed.")
+		}
+	}()
+
+	go func() {
+		defer close(batches)
+		debounce(ctx, o.debounce, changes, batches)
+	}()
+
+	return batches, nil
+}
+
+// debounce collects all changes from `changes` and sends them as one batch to `batches`
+// when no further change arrived during `wait`.
+func debounce(
+	ctx context.Context,
+	wait time.Duration,
+	changes <-chan string,
+	batches chan<- []string,
+) {
+	var batch []string
+	timer := time.NewTimer(wait)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p, ok := <-changes:
+			if !ok {
+				return
+			}
+
+			batch = append(batch, p)
+			timer.Reset(wait)
+		case <-timer.C:
+			slices.Sort(batch)
+
+			select {
+			case batches <- slices.Compact(batch):
+			case <-ctx.Done():
+				return
+			}
+
+			batch = nil
+		}
+	}
+}
+
+// WithDebounce sets the time to wait for further changes before
+// reporting a batch of changes.
+func WithDebounce(wait time.Duration) Option {
+	return func(o *options) error {
+		if wait < 0 {
+			return errors.New("debounce must not be negative: '%v'", wait)
+		}
+		o.debounce = wait
+
+		return nil
+	}
+}
+
+// WithPolling uses polling with interval `interval` instead of the native
+// file system events. A zero interval uses [DefaultPollInterval].
+func WithPolling(interval time.Duration) Option {
+	return func(o *options) error {
+		if interval < 0 {
+			return errors.New("poll interval must not be negative: '%v'", interval)
+		} else if interval != 0 {
+			o.pollInterval = interval
+		}
+		o.poll = true
+
+		return nil
+	}
+}
+
+// WithExclude excludes all directories for which `exclude` returns `true`
+// (and everything below).
+func WithExclude(exclude func(dir string) bool) Option {
+	return func(o *options) error {
+		o.exclude = exclude
+
+		return nil
+	}
+}
+
+func (o *options) isExcluded(dir string) bool {
+	return o.exclude != nil && o.exclude(dir)
+}
+
+// walkDirs calls `visit` for all non-excluded directories
+// below `dirs` (included).
+func walkDirs(dirs []string, excluded func(string) bool, visit func(dir string)) {
+	for _, d := range dirs {
+		_ = filepath.WalkDir(d, func(p string, e os.DirEntry, err error) error {
+			if err != nil {
+				// Vanished or unreadable, just skip it.
+				return nil //nolint:nilerr // intentional
+			}
+
+			if !e.IsDir() {
+				return nil
+			}
+
+			if excluded(p) {
+				return filepath.SkipDir
+			}
+
+			visit(p)
+
+			return nil
+		})
+	}
+}
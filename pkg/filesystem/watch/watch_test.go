@@ -0,0 +1,79 @@
+//go:build test && (test_small || test_all)
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitBatch(t *testing.T, batches <-chan []string) []string {
+	t.Helper()
+
+	select {
+	case b := <-batches:
+		return b
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no changes detected")
+	}
+
+	return nil
+}
+
+func testWatch(t *testing.T, opts ...Option) {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, fs.OutputDir), fs.DefaultPermissionsDir))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	batches, err := Watch(ctx, []string{dir},
+		append([]Option{
+			WithDebounce(50 * time.Millisecond),
+			WithExclude(func(d string) bool { return path.Base(d) == fs.OutputDir }),
+		}, opts...)...)
+	require.NoError(t, err)
+
+	// Excluded directories do not report changes.
+	require.NoError(t,
+		os.WriteFile(path.Join(dir, fs.OutputDir, "out"), []byte("a"), fs.DefaultPermissionsFile))
+
+	a := path.Join(dir, "a")
+	b := path.Join(dir, "b")
+	require.NoError(t, os.WriteFile(a, []byte("a"), fs.DefaultPermissionsFile))
+	require.NoError(t, os.WriteFile(b, []byte("b"), fs.DefaultPermissionsFile))
+	assert.Equal(t, []string{a, b}, waitBatch(t, batches))
+
+	// New directories are watched too.
+	sub := path.Join(dir, "sub")
+	require.NoError(t, os.MkdirAll(sub, fs.DefaultPermissionsDir))
+	time.Sleep(100 * time.Millisecond)
+
+	c := path.Join(sub, "c")
+	require.NoError(t, os.WriteFile(c, []byte("c"), fs.DefaultPermissionsFile))
+	assert.Equal(t, []string{c}, waitBatch(t, batches))
+
+	cancel()
+	for range batches { //nolint:revive // drain until closed.
+	}
+}
+
+func TestWatchNative(t *testing.T) {
+	t.Parallel()
+	testWatch(t)
+}
+
+func TestWatchPolling(t *testing.T) {
+	t.Parallel()
+	testWatch(t, WithPolling(20*time.Millisecond))
+}
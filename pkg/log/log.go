@@ -2,6 +2,7 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"sync"
@@ -152,6 +153,24 @@ func getStyles() *chlog.Styles {
 	return styles
 }
 
+// SetOutput redirects the output of the global logger to `w` (e.g. a view
+// on the terminal) and returns a function which restores the standard error.
+// The color profile of the standard error is kept.
+func SetOutput(w io.Writer) (restore func()) {
+	profile := lipgloss.NewRenderer(os.Stderr).ColorProfile()
+	if ciRunning() && ForceColorInCI {
+		profile = termenv.ANSI256
+	}
+
+	globalLogger.l.SetOutput(w)
+	globalLogger.l.SetColorProfile(profile)
+
+	return func() {
+		globalLogger.l.SetOutput(os.Stderr)
+		globalLogger.l.SetColorProfile(profile)
+	}
+}
+
 func SetLevel(level string) error {
 	return setLevel(globalLogger.l, level)
 }
@@ -2,9 +2,11 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	chlog "github.com/charmbracelet/log"
+	"github.com/muesli/termenv"
 )
 
 type logger struct {
@@ -18,6 +20,19 @@ func NewLogger(prefix string) ILog {
 	}
 }
 
+// NewLoggerWithOutput creates a threadsafe logger like [NewLogger]
+// which writes to `w` instead of the standard error.
+func NewLoggerWithOutput(prefix string, w io.Writer) ILog {
+	l := globalLogger.l.WithPrefix(prefix)
+	l.SetOutput(w)
+
+	if ciRunning() && ForceColorInCI {
+		l.SetColorProfile(termenv.ANSI256)
+	}
+
+	return &logger{l: l}
+}
+
 func (l logger) Trace(msg string, args ...any) {
 	l.l.Helper()
 	if l.l.GetLevel() <= TraceLevel {
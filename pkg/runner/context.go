@@ -1,6 +1,8 @@
 package runner
 
 import (
+	"context"
+
 	"github.com/sdsc-ordes/quitsh/pkg/component"
 	"github.com/sdsc-ordes/quitsh/pkg/component/step"
 	"github.com/sdsc-ordes/quitsh/pkg/component/target"
@@ -9,13 +11,23 @@ import (
 )
 
 type IContext interface {
+	// The execution context. It is cancelled when the execution
+	// is aborted (e.g. by a failing runner with fail-fast or a signal).
+	// Use it for all commands, e.g. with `exec.CmdContextBuilder.Context`,
+	// which also routes their output to the runner's log (see `exec.WithOutput`).
+	Ctx() context.Context
+
 	// The root directory of the repository.
+	// Runners must never rely on the process working directory (runners
+	// execute concurrently) but use this directory or the component's root
+	// (e.g. with `exec.CmdContextBuilder.Cwd`).
 	Root() string
 
 	// The Git context initialized at the root of the repository.
 	Git() git.Context
 
-	// The logger object.
+	// The logger object. In parallel mode it writes to the runner's log file
+	// (the same as all commands executed with `Ctx()`).
 	Log() log.ILog
 
 	// On which component the runner executes.
@@ -30,4 +42,13 @@ type IContext interface {
 
 	// The toolchain this runner runs in.
 	Toolchain() string
+
+	// The resolved outputs (absolute paths) of all targets
+	// this target directly depends on, keyed by their target id.
+	// Only dependencies which declare outputs are contained.
+	DependencyOutputs() map[target.ID][]string
+
+	// The matrix parameters of the target (empty if the target
+	// is not expanded from a matrix).
+	Parameters() target.Parameters
 }
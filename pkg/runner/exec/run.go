@@ -1,9 +1,12 @@
 package execrunner
 
 import (
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/sdsc-ordes/quitsh/pkg/common"
+	"github.com/sdsc-ordes/quitsh/pkg/component/target"
 	"github.com/sdsc-ordes/quitsh/pkg/debug"
 	"github.com/sdsc-ordes/quitsh/pkg/exec"
 	fs "github.com/sdsc-ordes/quitsh/pkg/filesystem"
@@ -31,6 +34,27 @@ func (*ExecRunner) ID() runner.RegisterID {
 	return ExecRunnerID
 }
 
+// ParameterEnvVariables returns the matrix parameters `params` as
+// env. variables `QUITSH_MATRIX_<NAME>=<value>` (sorted by name).
+// The name is uppercased and all characters other than `[A-Z0-9_]` are replaced by `_`.
+func ParameterEnvVariables(params target.Parameters) []string {
+	env := make([]string, 0, len(params))
+
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		key := strings.Map(func(r rune) rune {
+			if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+				return r
+			}
+
+			return '_'
+		}, strings.ToUpper(name))
+
+		env = append(env, "QUITSH_MATRIX_"+key+"="+params[name])
+	}
+
+	return env
+}
+
 func (r *ExecRunner) Run(ctx runner.IContext) error {
 	log := ctx.Log()
 	comp := ctx.Component()
@@ -38,12 +62,14 @@ func (r *ExecRunner) Run(ctx runner.IContext) error {
 	fs.AssertDirs(comp.OutBuildBinDir())
 
 	cmdCtx := exec.NewCmdCtxBuilder().
+		Context(ctx.Ctx()).
 		Cwd(comp.Root()).
 		Env(r.config.Env...).
 		Env(comp.OutEnvVariables()...).
 		Env(
 			"QUITSH_BUILD_TYPE="+r.settings.BuildType().String(),
 			"QUITSH_ENVIRONMENT_TYPE="+r.settings.EnvironmentType().String()).
+		Env(ParameterEnvVariables(ctx.Parameters())...).
 		Build()
 
 	if r.config.Script != "" {
@@ -86,6 +86,7 @@ func (r *GoBuildRunner) Run(ctx runner.IContext) error {
 		}
 
 		goctx := gox.NewCtxBuilder().
+			Context(ctx.Ctx()).
 			Cwd(comp.Root()).
 			Env("GOBIN="+binDir,
 				"GOWORK="+r.config.GoWork,
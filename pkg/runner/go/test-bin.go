@@ -1,6 +1,7 @@
 package gorunner
 
 import (
+	"context"
 	"os"
 	"path"
 
@@ -38,6 +39,7 @@ func (r *GoTestBinRunner) ID() runner.RegisterID {
 }
 
 func buildBinary(
+	ctx context.Context,
 	log log.ILog,
 	comp *component.Component,
 	setts config.ITestSettings,
@@ -48,6 +50,7 @@ func buildBinary(
 	log.Info("Build instrumented binaries.")
 
 	goctx := gox.NewCtxBuilder().
+		Context(ctx).
 		Cwd(comp.Root()).
 		Env(os.Environ()...).
 		Env("GOBIN="+outputDir,
@@ -94,6 +97,7 @@ func buildBinary(
 }
 
 func testBinary(
+	ctx context.Context,
 	log log.ILog,
 	comp *component.Component,
 	setts config.ITestSettings,
@@ -106,6 +110,7 @@ func testBinary(
 		"QUITSH_BIN_DIR=" + comp.OutCoverageBinDir(),
 		"QUITSH_COVERAGE_DIR=" + comp.OutCoverageDataDir()}
 	goctx := gox.NewCtxBuilder().
+		Context(ctx).
 		Cwd(comp.Root()).
 		Env(envs...).
 		Build()
@@ -162,6 +167,7 @@ func (r *GoTestBinRunner) Run(ctx runner.IContext) error {
 	}
 
 	err = buildBinary(
+		ctx.Ctx(),
 		log,
 		comp,
 		r.settings,
@@ -176,6 +182,7 @@ func (r *GoTestBinRunner) Run(ctx runner.IContext) error {
 	}
 
 	err = testBinary(
+		ctx.Ctx(),
 		log,
 		comp,
 		r.settings,
@@ -188,7 +195,7 @@ func (r *GoTestBinRunner) Run(ctx runner.IContext) error {
 		return err
 	}
 
-	err = generateCoverageReport(log, comp)
+	err = generateCoverageReport(ctx.Ctx(), log, comp)
 	if err != nil {
 		log.ErrorE(err, "Generating coverage report failed.")
 	}
@@ -1,6 +1,7 @@
 package gorunner
 
 import (
+	"context"
 	"path"
 
 	cm "github.com/sdsc-ordes/quitsh/pkg/common"
@@ -33,13 +34,13 @@ func (r *GoTestRunner) ID() runner.RegisterID {
 	return GoTestRunnerID
 }
 
-func generateCoverageReport(log log.ILog, comp *component.Component) error {
+func generateCoverageReport(ctx context.Context, log log.ILog, comp *component.Component) error {
 	covDataDir := comp.OutCoverageDataDir()
 	covHTML := comp.OutCoverageDataDir("coverage.html")
 	covFile := comp.OutCoverageDataDir("coverage.txt")
 	log.Info("Generating coverage file.", "path", "file://"+covHTML)
 
-	goctx := gox.NewCtxBuilder().Cwd(comp.Root()).Build()
+	goctx := gox.NewCtxBuilder().Context(ctx).Cwd(comp.Root()).Build()
 
 	err := goctx.Chain().
 		Check("tool", "covdata", "textfmt", "-i", covDataDir, "-o", covFile).
@@ -66,7 +67,7 @@ func (r *GoTestRunner) Run(ctx runner.IContext) error {
 	config := comp.Config()
 	log.Info("Starting Go test for component.", "component", config.Name)
 
-	goctx := gox.NewCtxBuilder().Cwd(comp.Root()).
+	goctx := gox.NewCtxBuilder().Context(ctx.Ctx()).Cwd(comp.Root()).
 		Env("GOWORK="+r.config.GoWork,
 			"GOTOOLCHAIN="+r.config.GoToolchain).
 		Build()
@@ -137,7 +138,7 @@ func (r *GoTestRunner) Run(ctx runner.IContext) error {
 		}
 	}
 
-	err = generateCoverageReport(log, comp)
+	err = generateCoverageReport(ctx.Ctx(), log, comp)
 	if err != nil {
 		log.ErrorE(err, "Go coverage conversion failed.")
 	}
@@ -1,6 +1,8 @@
 package toolchain
 
 import (
+	"context"
+
 	"github.com/sdsc-ordes/quitsh/pkg/common"
 	"github.com/sdsc-ordes/quitsh/pkg/component/step"
 	"github.com/sdsc-ordes/quitsh/pkg/component/target"
@@ -12,6 +14,7 @@ import (
 // run the runner over a toolchain.
 type IDispatcher interface {
 	Run(
+		ctx context.Context,
 		repoDir string,
 		dispatchArgs *DispatchArgs,
 		config config.IConfig,
@@ -26,6 +29,9 @@ type DispatchArgs struct {
 
 	RunnerID  runner.RegisterID `yaml:"runnerID"  validate:""`
 	Toolchain string            `yaml:"toolchain" validate:""`
+
+	// The resolved outputs of the dependencies of the target.
+	DependencyOutputs map[target.ID][]string `yaml:"dependencyOutputs,omitempty"`
 }
 
 // Validate validates the dispatch args.
@@ -1,6 +1,7 @@
 package nixtoolchain
 
 import (
+	"context"
 	"os"
 	"path"
 
@@ -70,6 +71,7 @@ func storeConfig(config config.IConfig) (file string, cleanup func(), err error)
 }
 
 func (d *NixDispatcher) Run(
+	ctx context.Context,
 	rootDir string,
 	dArgs *toolchain.DispatchArgs,
 	config config.IConfig,
@@ -84,6 +86,7 @@ func (d *NixDispatcher) Run(
 	args.RunnerIndex = dArgs.RunnerIndex
 	args.RunnerID = dArgs.RunnerID
 	args.Toolchain = dArgs.Toolchain
+	args.DependencyOutputs = dArgs.DependencyOutputs
 
 	file, cleanup, err := storeConfig(configCopy)
 	if err != nil {
@@ -96,7 +99,7 @@ func (d *NixDispatcher) Run(
 	log.Info("Dispatching to toolchain.", "toolchain", nixToolchainRef)
 
 	// Call the tool again, but over Nix.
-	nixctx := NewCtxBuilder(rootDir, flakePath, dArgs.Toolchain).Build()
+	nixctx := NewCtxBuilder(rootDir, flakePath, dArgs.Toolchain).Context(ctx).Build()
 	nixCmd := append([]string{os.Args[0]}, d.command...)
 	nixCmd = append(nixCmd, "--config", file)
 
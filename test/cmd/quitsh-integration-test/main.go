@@ -10,9 +10,14 @@ import (
 	exrunner "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/exec-runner"
 	exstage "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/exec-stage"
 	extarget "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/exec-target"
+	graphcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/graph"
 	listcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/list"
+	plancmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/plan"
 	processcompose "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/process-compose"
+	querycmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/query"
 	rootcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/root"
+	watchcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/watch"
+	whycmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/why"
 	"github.com/sdsc-ordes/quitsh/pkg/common"
 	"github.com/sdsc-ordes/quitsh/pkg/component/query"
 	"github.com/sdsc-ordes/quitsh/pkg/component/stage"
@@ -113,9 +118,14 @@ func main() {
 	exrunner.AddCmd(cli, cli.RootCmd(), &args.Commands.DispatchArgs)
 	extarget.AddCmd(cli, cli.RootCmd(), &args.Commands.ExecArgs)
 	exstage.AddCmdGeneral(cli, cli.RootCmd(), &args.Commands.ExecArgs)
+	watchcmd.AddCmd(cli, cli.RootCmd(), &args.Commands.ExecArgs)
 	exstage.AddCmdAlias(cli, cli.RootCmd(), stage.Stage("build"), &args.Commands.ExecArgs)
 	configcmd.AddCmd(cli.RootCmd(), &args)
 	listcmd.AddCmd(cli, cli.RootCmd())
+	plancmd.AddCmd(cli, cli.RootCmd())
+	graphcmd.AddCmd(cli, cli.RootCmd())
+	whycmd.AddCmd(cli, cli.RootCmd())
+	querycmd.AddCmd(cli, cli.RootCmd())
 	processcompose.AddCmd(cli, cli.RootCmd(), flakeDir)
 
 	// Register the common cmd runner.
@@ -12,9 +12,14 @@ import (
 	configcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/config"
 	execrunner "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/exec-runner"
 	exectarget "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/exec-target"
+	graphcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/graph"
 	listcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/list"
+	plancmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/plan"
 	pccmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/process-compose"
+	querycmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/query"
 	versionupcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/version-up"
+	watchcmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/watch"
+	whycmd "github.com/sdsc-ordes/quitsh/pkg/cli/cmd/why"
 	"github.com/sdsc-ordes/quitsh/pkg/common"
 	"github.com/sdsc-ordes/quitsh/pkg/component/query"
 	"github.com/sdsc-ordes/quitsh/pkg/config"
@@ -69,8 +74,13 @@ func main() {
 	// Setup quitsh provided helper commands.
 	versionupcmd.AddCmd(cli, cli.RootCmd())
 	listcmd.AddCmd(cli, cli.RootCmd())
+	plancmd.AddCmd(cli, cli.RootCmd())
+	graphcmd.AddCmd(cli, cli.RootCmd())
+	whycmd.AddCmd(cli, cli.RootCmd())
+	querycmd.AddCmd(cli, cli.RootCmd())
 	configcmd.AddCmd(cli.RootCmd(), &conf)
 	exectarget.AddCmd(cli, cli.RootCmd(), &conf.Commands.ExecArgs)
+	watchcmd.AddCmd(cli, cli.RootCmd(), &conf.Commands.ExecArgs)
 	execrunner.AddCmd(cli, cli.RootCmd(), &conf.Commands.DispatchArgs)
 	pccmd.AddCmd(cli, cli.RootCmd(), flakeDirRel)
 
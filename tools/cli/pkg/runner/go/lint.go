@@ -1,6 +1,7 @@
 package gorunner
 
 import (
+	"context"
 	"path"
 	"quitsh-cli/pkg/runner/config"
 	"quitsh-cli/pkg/setup"
@@ -61,21 +62,22 @@ func (r *GoLintRunner) ID() runner.RegisterID {
 func (r *GoLintRunner) Run(ctx runner.IContext) error {
 	comp := ctx.Component()
 
-	err := runGoModTidy(ctx.Log(), comp)
+	err := runGoModTidy(ctx.Ctx(), ctx.Log(), comp)
 
-	e := runGoLangCILint(ctx.Log(), comp, ctx.Root())
+	e := runGoLangCILint(ctx.Ctx(), ctx.Log(), comp, ctx.Root())
 	err = errors.Combine(e, err)
 
-	e = runNoWrongIncludes(ctx.Log(), comp)
+	e = runNoWrongIncludes(ctx.Ctx(), ctx.Log(), comp)
 	err = errors.Combine(e, err)
 
 	return err
 }
 
-func runGoModTidy(log log.ILog, comp *component.Component) error {
+func runGoModTidy(ctx context.Context, log log.ILog, comp *component.Component) error {
 	log.Info("Starting `no-go-mod-tidy-changes`.", "component", comp.Config().Name)
 
 	goctx := gox.NewCtxBuilder().
+		Context(ctx).
 		Cwd(comp.Root()).
 		Build()
 
@@ -102,12 +104,13 @@ func runGoModTidy(log log.ILog, comp *component.Component) error {
 	return nil
 }
 
-func runNoWrongIncludes(log log.ILog, comp *component.Component) error {
+func runNoWrongIncludes(ctx context.Context, log log.ILog, comp *component.Component) error {
 	log.Info("Starting `no-wrong-includes`.", "component", comp.Config().Name)
 
 	var noIncAs []string
 
 	grep := exec.NewCmdCtxBuilder().
+		Context(ctx).
 		BaseCmd("grep").
 		BaseArgs(
 			"-r",
@@ -166,7 +169,12 @@ func runNoWrongIncludes(log log.ILog, comp *component.Component) error {
 	return err
 }
 
-func runGoLangCILint(log log.ILog, comp *component.Component, rootDir string) error {
+func runGoLangCILint(
+	ctx context.Context,
+	log log.ILog,
+	comp *component.Component,
+	rootDir string,
+) error {
 	log.Info("Starting `golangcilint` for component.", "component", comp.Config().Name)
 
 	err := setup.LinkConfigFiles(rootDir)
@@ -175,6 +183,7 @@ func runGoLangCILint(log log.ILog, comp *component.Component, rootDir string) er
 	}
 
 	lintctx := exec.NewCmdCtxBuilder().
+		Context(ctx).
 		BaseCmd("golangci-lint").
 		Cwd(comp.Root()).
 		ExitCodeHandler(